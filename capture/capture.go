@@ -0,0 +1,217 @@
+/*
+Package capture records raw telemetry payloads to a file and reads them back.
+
+A capture file starts with the magic "GRPCCAP1", followed by one record per
+payload. Every record is prefixed with its length as big endian uint32 and
+consists of:
+
+	receive time    int64, nanoseconds since the Unix epoch, big endian
+	transport       uint8
+	peer length     uint16, big endian
+	peer            peer address
+	payload         rest of the record
+*/
+
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Magic at the start of every capture file
+const Magic = "GRPCCAP1"
+
+// MaxRecordSize limits the records accepted by the reader
+const MaxRecordSize = 64 << 20
+
+// Size of the fixed record fields
+const headerSize = 8 + 1 + 2
+
+// ErrFormat is returned for files or records that are not valid
+var ErrFormat = errors.New("invalid capture format")
+
+// Transport a payload was received on
+type Transport uint8
+
+// Transports
+const (
+	// MdtDialout payloads are MdtDialoutArgs.Data
+	MdtDialout Transport = iota + 1
+
+	// MdtDialIn payloads are CreateSubsReply.Data
+	MdtDialIn
+
+	// GNMI payloads are serialized SubscribeResponse messages of a dial-in subscription
+	GNMI
+
+	// GNMIDialOut payloads are serialized SubscribeResponse messages published by a target
+	GNMIDialOut
+)
+
+var transportNames = map[Transport]string{
+	MdtDialout:  "mdt-dialout",
+	MdtDialIn:   "mdt-dialin",
+	GNMI:        "gnmi",
+	GNMIDialOut: "gnmi-dialout",
+}
+
+func (t Transport) String() string {
+	if name, ok := transportNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("transport(%d)", uint8(t))
+}
+
+// Record is a raw payload with its origin
+type Record struct {
+	Time      time.Time
+	Transport Transport
+	Peer      string
+	Data      []byte
+}
+
+// Writer appends records to a capture, it is safe for concurrent use
+type Writer struct {
+	mu     sync.Mutex
+	writer *bufio.Writer
+	closer io.Closer
+}
+
+// NewWriter writes the magic to w and returns a writer for the records
+func NewWriter(w io.Writer) (*Writer, error) {
+	writer := &Writer{writer: bufio.NewWriter(w)}
+	if _, err := writer.writer.WriteString(Magic); err != nil {
+		return nil, err
+	}
+	return writer, writer.writer.Flush()
+}
+
+// Create a capture file, an existing file is truncated
+func Create(name string) (*Writer, error) {
+	file, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewWriter(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	writer.closer = file
+	return writer, nil
+}
+
+// Write a record, records are flushed immediately so a capture survives a crash
+func (w *Writer) Write(record *Record) error {
+	if len(record.Peer) > 0xffff {
+		return fmt.Errorf("%w: peer address too long", ErrFormat)
+	}
+	size := headerSize + len(record.Peer) + len(record.Data)
+	if size > MaxRecordSize {
+		return fmt.Errorf("%w: record of %d bytes exceeds the maximum size", ErrFormat, size)
+	}
+
+	header := make([]byte, 4+headerSize)
+	binary.BigEndian.PutUint32(header, uint32(size))
+	binary.BigEndian.PutUint64(header[4:], uint64(record.Time.UnixNano()))
+	header[12] = byte(record.Transport)
+	binary.BigEndian.PutUint16(header[13:], uint16(len(record.Peer)))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writer.Write(header)
+	w.writer.WriteString(record.Peer)
+	w.writer.Write(record.Data)
+	return w.writer.Flush()
+}
+
+// Close the underlying file if the writer was created by Create
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.writer.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Reader reads the records of a capture
+type Reader struct {
+	reader *bufio.Reader
+	closer io.Closer
+}
+
+// NewReader checks the magic of r and returns a reader for the records
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{reader: bufio.NewReader(r)}
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(reader.reader, magic); err != nil || string(magic) != Magic {
+		return nil, fmt.Errorf("%w: missing magic", ErrFormat)
+	}
+	return reader, nil
+}
+
+// Open a capture file
+func Open(name string) (*Reader, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	reader.closer = file
+	return reader, nil
+}
+
+// Next returns the next record, io.EOF at the end of the capture and
+// io.ErrUnexpectedEOF for a truncated record
+func (r *Reader) Next() (*Record, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.reader, prefix[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(prefix[:])
+	if size < headerSize || size > MaxRecordSize {
+		return nil, fmt.Errorf("%w: record size %d", ErrFormat, size)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	peerLen := int(binary.BigEndian.Uint16(buf[9:]))
+	if headerSize+peerLen > len(buf) {
+		return nil, fmt.Errorf("%w: peer exceeds record", ErrFormat)
+	}
+	return &Record{
+		Time:      time.Unix(0, int64(binary.BigEndian.Uint64(buf))),
+		Transport: Transport(buf[8]),
+		Peer:      string(buf[headerSize : headerSize+peerLen]),
+		Data:      buf[headerSize+peerLen:],
+	}, nil
+}
+
+// Close the underlying file if the reader was created by Open
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
@@ -6,110 +6,199 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dialin"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"github.com/CiscoSE/grpc_collector/output"
+)
 
-	"github.com/golang/protobuf/proto"
-	xr "github.com/nleiva/xrgrpc"
+var (
+	paths      = flag.String("paths", "", "Comma separated sensor paths for an ad-hoc subscription, uses the pre-configured subscription if empty")
+	interval   = flag.Duration("interval", 5*time.Second, "Sample interval of the ad-hoc subscription")
+	record     = flag.String("record", "", "Record the raw telemetry payloads to this capture file")
+	deadLetter = flag.String("dead-letter", "", "Append the messages and rows that fail to decode to this file")
 
-	lldp "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry/cisco_ios_xr_ethernet_lldp_oper/lldp/nodes/node/neighbors/summaries/summary"
-	"github.com/nleiva/xrgrpc/proto/telemetry"
+	// Limits of the decoded messages, zero is unlimited
+	maxDepth       = flag.Int("max-depth", 32, "Maximum nesting of GPB-KV fields, deeper fields are dropped")
+	maxFields      = flag.Int("max-fields", 10000, "Maximum fields per measurement, further GPB-KV fields are dropped")
+	maxMessageSize = flag.Int("max-message-size", 4<<20, "Maximum size of a telemetry message in bytes, larger messages are rejected")
 )
 
 func main() {
+	flag.Parse()
 
 	// Determine the ID for first the transaction.
 	var id int64 = 1000
 
 	// Manually specify target parameters.
-	router1, err := xr.BuildRouter(
-		xr.WithUsername("YOUR_USER"),         // e.g. admin
-		xr.WithPassword("YOUR_PASS"),         // e.g. cisco123
-		xr.WithHost("YOUR_DEVICE:GRPC_PORT"), // e.g. 192.168.0.1:57344
-		xr.WithTimeout(60),
-	)
-	if err != nil {
-		log.Fatalf("target parameters for router1 are incorrect: %s", err)
+	router1 := dialin.Router{
+		Username: "YOUR_USER",             // e.g. admin
+		Password: "YOUR_PASS",             // e.g. cisco123
+		Host:     "YOUR_DEVICE:GRPC_PORT", // e.g. 192.168.0.1:57344
+		Timeout:  60 * time.Second,
 	}
 
+	ctx1, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Connect to the targets
-	conn1, ctx1, err := xr.Connect(*router1)
+	client1, err := dialin.Dial(ctx1, router1)
 	if err != nil {
 		log.Fatalf("could not setup a client connection to %s, %v", router1.Host, err)
 	}
-	defer conn1.Close()
+	defer client1.Close()
+
+	// Open the capture file
+	if len(*record) > 0 {
+		client1.Recorder, err = capture.Create(*record)
+		if err != nil {
+			log.Fatalf("could not create capture file: %v", err)
+		}
+		defer client1.Recorder.Close()
+		log.Printf("Recording raw payloads to %s\n", *record)
+	}
+
+	// Decode the paths with a compact type in this repository
+	decoder := &mdt.Decoder{
+		Compact:        mdt.KnownCompact(),
+		Counters:       &mdt.Counters{},
+		MaxDepth:       *maxDepth,
+		MaxFields:      *maxFields,
+		MaxMessageSize: *maxMessageSize,
+	}
+
+	// Open the dead-letter file
+	if len(*deadLetter) > 0 {
+		decoder.DeadLetter, err = output.CreateDeadLetter(*deadLetter)
+		if err != nil {
+			log.Fatalf("could not create dead-letter file: %v", err)
+		}
+		defer decoder.DeadLetter.Close()
+		log.Printf("Writing rejected payloads to %s\n", *deadLetter)
+	}
 
-	// Get the BGP config from one of the devices
-	id++
-	ctx1, cancel := context.WithCancel(ctx1)
-	defer cancel()
 	c := make(chan os.Signal, 1)
 	// If no signals are provided, all incoming signals will be relayed to c.
-	// Otherwise, just the provided signals will. E.g.: signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	signal.Notify(c, os.Interrupt)
+	// Otherwise, just the provided signals will. SIGTERM is trapped too, so the
+	// ad-hoc subscription is removed from the router when the process is stopped.
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	defer func() {
 		signal.Stop(c)
 		cancel()
 	}()
 
-	// Telemetry Subscription
-	p := "lldp-dial-in-subs" // Change as needed, this should be configured on the device in advance. See README file for instructions
-	// encoding GPB
-	var e int64 = 2
-	ch, ech, err := xr.GetSubscription(ctx1, conn1, p, id, e)
-	if err != nil {
-		log.Fatalf("could not setup Telemetry Subscription: %v\n", err)
-	}
-
 	go func() {
 		select {
 		case <-c:
 			fmt.Printf("\nmanually cancelled the session to %v\n\n", router1.Host)
 			cancel()
-			return
 		case <-ctx1.Done():
-			// Timeout: "context deadline exceeded"
-			err = ctx1.Err()
-			fmt.Printf("\ngRPC session timed out after %v seconds: %v\n\n", router1.Timeout, err.Error())
-			return
-		case err = <-ech:
-			// Session canceled: "context canceled"
-			fmt.Printf("\ngRPC session to %v failed: %v\n\n", router1.Host, err.Error())
-			return
 		}
 	}()
 
+	// Telemetry Subscription
+	p := "lldp-dial-in-subs" // Change as needed, this should be configured on the device in advance. See README file for instructions
+	if len(*paths) > 0 {
+		// Push a temporary sensor-group and subscription for the requested paths
+		adhoc := dialin.AdHoc{
+			Name:     fmt.Sprintf("grpc-collector-%d", os.Getpid()),
+			Paths:    strings.Split(*paths, ","),
+			Interval: *interval,
+		}
+		id++
+		if err = client1.Configure(ctx1, id, adhoc); err != nil {
+			log.Printf("could not configure ad-hoc subscription, falling back to gNMI: %v\n", err)
+			fmt.Printf("\ngNMI telemetry from %s\n\n", router1.Host)
+			if err := client1.StreamGNMI(ctx1, adhoc, os.Stdout); err != nil {
+				fmt.Printf("\ngNMI session to %v failed: %v\n\n", router1.Host, err.Error())
+			}
+			return
+		}
+		log.Printf("Configured ad-hoc subscription %s on %s\n", adhoc.Name, router1.Host)
+		p = adhoc.Name
+
+		// The session context is already cancelled when this runs, clean up with a new one
+		defer func() {
+			cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cleanupCancel()
+			id++
+			if err := client1.Unconfigure(cleanupCtx, id, adhoc); err != nil {
+				log.Printf("could not remove ad-hoc subscription %s: %v\n", adhoc.Name, err)
+				return
+			}
+			log.Printf("Removed ad-hoc subscription %s from %s\n", adhoc.Name, router1.Host)
+		}()
+	}
+
 	fmt.Printf("\ntelemetry from %s\n\n", router1.Host)
+	id++
+	err = collect(ctx1, client1, dialin.Subscription{
+		ReqID:         id,
+		Encoding:      dialin.EncodingGPB,
+		Subscriptions: []string{p},
+	}, decoder, os.Stdout)
+	if err != nil {
+		fmt.Printf("\ngRPC session to %v failed: %v\n\n", router1.Host, err.Error())
+	}
 
-	for tele := range ch {
-		log.Printf("***** New message from %v ***** \n", router1.Host)
-		message := new(telemetry.Telemetry)
+	fmt.Printf("\nReceived telemetry:\n")
+	decoder.Counters.Print(os.Stdout)
+}
+
+// Subscribe and print every decoded row to w until the stream ends, the LLDP
+// neighbor summary as a list of neighbors and other paths with all fields. The error ending the stream is returned, nil if it was closed
+// by the router or ctx. Device errors are reported as *dialin.DeviceError.
+func collect(ctx context.Context, client *dialin.Client, sub dialin.Subscription, decoder *mdt.Decoder, w io.Writer) error {
+	ch, ech, err := client.Subscribe(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("could not setup Telemetry Subscription: %w", err)
+	}
 
-		err = proto.Unmarshal(tele, message)
+	printer := output.NewPrinter(w)
+	for tele := range ch {
+		log.Printf("***** New message from %v ***** \n", client.Router.Host)
+		metrics, err := decoder.Decode(tele)
 		if err != nil {
-			log.Printf("Could not unmarshall the interface telemetry message for %v: %v\n", router1.Host, err)
+			log.Printf("Could not decode the telemetry message for %v: %v\n", client.Router.Host, err)
 		}
-		for _, row := range message.GetDataGpb().GetRow() {
-			// Get the message content
-
-			content := row.GetContent()
-			//fmt.Printf("\n\n%v\n\n\n", hex.Dump(content))
-			nbr := new(lldp.LldpNeighbor)
-			err = proto.Unmarshal(content, nbr)
-			if err != nil {
-				log.Fatalf("Could decode Content: %v\n", err)
+		for _, metric := range metrics {
+			if metric.Name == mdt.PathLLDPSummary {
+				printNeighbors(w, metric)
+			} else if err := printer.Write(metric); err != nil {
+				return err
 			}
-			for _, item := range nbr.GetLldpNeighbor() {
-				deviceId := item.GetDeviceId()
+		}
+	}
 
-				capabilities := item.GetEnabledCapabilities()
-				chassisId := item.GetChassisId()
-				// Print result:
-				fmt.Printf("Device: %v\n Cappabilities: %v\n Chassis ID: %v\n\n", deviceId, capabilities, chassisId)
+	select {
+	case err := <-ech:
+		if ctx.Err() == nil {
+			return err
+		}
+	default:
+	}
+	return nil
+}
 
-			}
+// Print the LLDP neighbors of a decoded summary row
+func printNeighbors(w io.Writer, metric *output.Metric) {
+	for i := 0; ; i++ {
+		prefix := fmt.Sprintf("lldp_neighbor/%d/", i)
+		deviceID, ok := metric.Fields[prefix+"device_id"]
+		if !ok {
+			return
 		}
+		fmt.Fprintf(w, "Device: %v\n Cappabilities: %v\n Chassis ID: %v\n\n",
+			deviceID, metric.Fields[prefix+"enabled_capabilities"], metric.Fields[prefix+"chassis_id"])
 	}
 }
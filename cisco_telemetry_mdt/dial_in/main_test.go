@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dialin"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/proto"
+)
+
+const subscription = "lldp-dial-in-subs"
+
+// Emulated router sending count samples of two rows of path, or the payloads if set
+func startRouter(t *testing.T, path string, count int, payloads ...[]byte) *dialin.Client {
+	t.Helper()
+	router := &emulator.Router{
+		Name:     "router",
+		Username: "admin",
+		Password: "secret",
+		Subscriptions: map[string]emulator.RouterSubscription{
+			subscription: {Paths: []string{path}, Payloads: payloads},
+		},
+		Rows:     2,
+		Interval: 10 * time.Millisecond,
+		Count:    count,
+	}
+	address, err := router.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(router.Stop)
+
+	client, err := dialin.Dial(context.Background(), dialin.Router{
+		Host:     address,
+		Username: "admin",
+		Password: "secret",
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// Decoder of the collector, with the dead letters written to letters
+func newDecoder(letters *bytes.Buffer) *mdt.Decoder {
+	return &mdt.Decoder{
+		Compact:    mdt.KnownCompact(),
+		Counters:   &mdt.Counters{},
+		DeadLetter: output.NewDeadLetter(letters),
+	}
+}
+
+var lldpSub = dialin.Subscription{ReqID: 1, Encoding: dialin.EncodingGPB, Subscriptions: []string{subscription}}
+
+func TestCollect(t *testing.T) {
+	client := startRouter(t, mdt.PathLLDPSummary, 3)
+	var letters, out bytes.Buffer
+	decoder := newDecoder(&letters)
+	if err := collect(context.Background(), client, lldpSub, decoder, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, neighbor := range []string{"Device: router-peer-0\n", "Device: router-peer-1\n", "Chassis ID: 00ca.fe00.0001\n"} {
+		if got := strings.Count(out.String(), neighbor); got != 3 {
+			t.Errorf("got %q %d times, want 3 in:\n%s", neighbor, got, out.String())
+		}
+	}
+	want := mdt.Count{Messages: 3, Rows: 6}
+	if got := decoder.Counters.Counts()[mdt.CounterKey{Device: "router", Path: mdt.PathLLDPSummary}]; got != want {
+		t.Errorf("got count %+v, want %+v", got, want)
+	}
+	if letters.Len() != 0 {
+		t.Errorf("unexpected dead letters %s", letters.String())
+	}
+}
+
+// Rows of other known paths are printed with all fields
+func TestCollectOtherPath(t *testing.T) {
+	client := startRouter(t, mdt.PathURIB, 1)
+	var letters, out bytes.Buffer
+	decoder := newDecoder(&letters)
+	if err := collect(context.Background(), client, lldpSub, decoder, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{"Measurement: " + mdt.PathURIB + "\n", "next_hop/0/address: "} {
+		if got := strings.Count(out.String(), field); got != 2 {
+			t.Errorf("got %q %d times, want 2 in:\n%s", field, got, out.String())
+		}
+	}
+	want := mdt.Count{Messages: 1, Rows: 2}
+	if got := decoder.Counters.Counts()[mdt.CounterKey{Device: "router", Path: mdt.PathURIB}]; got != want {
+		t.Errorf("got count %+v, want %+v", got, want)
+	}
+}
+
+// A row that fails to decode is skipped and stored, the other row is printed
+func TestCollectInvalidRow(t *testing.T) {
+	message, err := (&emulator.Generator{Node: "router", Subscription: subscription, Path: mdt.PathLLDPSummary, Encoding: emulator.GPB, Rows: 2}).Next(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message.DataGpb.Row[0].Content = []byte{0x0a, 0x05, 0x01}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startRouter(t, mdt.PathLLDPSummary, 1, data)
+	var letters, out bytes.Buffer
+	decoder := newDecoder(&letters)
+	if err := collect(context.Background(), client, lldpSub, decoder, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(out.String(), "router-peer-0") || !strings.Contains(out.String(), "Device: router-peer-1\n") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	want := mdt.Count{Messages: 1, Rows: 1, Errors: 1}
+	if got := decoder.Counters.Counts()[mdt.CounterKey{Device: "router", Path: mdt.PathLLDPSummary}]; got != want {
+		t.Errorf("got count %+v, want %+v", got, want)
+	}
+	if !strings.Contains(letters.String(), `"row":0`) {
+		t.Errorf("row not stored as dead letter: %s", letters.String())
+	}
+}
+
+// Subscriptions missing on the router end with a device error
+func TestCollectDeviceError(t *testing.T) {
+	client := startRouter(t, mdt.PathLLDPSummary, 1)
+	sub := lldpSub
+	sub.Subscriptions = []string{"missing"}
+	var letters, out bytes.Buffer
+	err := collect(context.Background(), client, sub, newDecoder(&letters), &out)
+	var deviceErr *dialin.DeviceError
+	if !errors.As(err, &deviceErr) {
+		t.Errorf("got error %v, want a device error", err)
+	}
+}
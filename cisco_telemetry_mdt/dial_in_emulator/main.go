@@ -0,0 +1,156 @@
+/*
+Emulate an IOS-XR router serving telemetry subscriptions to gRPC dial-in collectors
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	listen    = flag.String("listen", ":57344", "Address to serve gRPC dial-in on")
+	name      = flag.String("name", "emulated-router", "Node ID sent in the generated messages")
+	username  = flag.String("username", "", "Username required from collectors, not checked if empty")
+	password  = flag.String("password", "", "Password required from collectors")
+	tlsCert   = flag.String("tls-cert", "", "TLS certificate file, plain text if empty")
+	tlsKey    = flag.String("tls-key", "", "TLS key file")
+	rows      = flag.Int("rows", 10, "Rows per generated message")
+	interval  = flag.Duration("interval", 5*time.Second, "Interval between samples")
+	count     = flag.Int("count", 0, "Number of samples per stream, zero streams until the collector cancels")
+	malformed = flag.Float64("malformed", 0, "Fraction of generated messages sent malformed")
+	errorRate = flag.Float64("error-rate", 0, "Fraction of samples replaced by an error, which ends the stream")
+	errorText = flag.String("error-text", "emulated router error", "Text of the errors")
+
+	subscriptions, captures stringList
+)
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func init() {
+	flag.Var(&subscriptions, "subscription", "Subscription as name=path,path with generated data, can be repeated")
+	flag.Var(&captures, "capture", "Subscription as name=file with the MDT payloads of a capture file, can be repeated")
+}
+
+// Split a name=value flag
+func splitFlag(value string) (string, string, error) {
+	i := strings.Index(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return "", "", fmt.Errorf("%q is not name=value", value)
+	}
+	return value[:i], value[i+1:], nil
+}
+
+// Read the MDT payloads of a capture file
+func readPayloads(file string) ([][]byte, error) {
+	reader, err := capture.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var payloads [][]byte
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		if record.Transport == capture.MdtDialout || record.Transport == capture.MdtDialIn {
+			payloads = append(payloads, record.Data)
+		}
+	}
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("%s: no MDT payloads", file)
+	}
+	return payloads, nil
+}
+
+func main() {
+	flag.Parse()
+
+	router := &emulator.Router{
+		Name:          *name,
+		Username:      *username,
+		Password:      *password,
+		Subscriptions: make(map[string]emulator.RouterSubscription),
+		Rows:          *rows,
+		Interval:      *interval,
+		Count:         *count,
+		Malformed:     *malformed,
+		ErrorRate:     *errorRate,
+		ErrorText:     *errorText,
+	}
+
+	for _, value := range subscriptions {
+		subscription, paths, err := splitFlag(value)
+		if err != nil {
+			log.Fatalf("Invalid subscription: %v", err)
+		}
+		router.Subscriptions[subscription] = emulator.RouterSubscription{Paths: strings.Split(paths, ",")}
+	}
+	for _, value := range captures {
+		subscription, file, err := splitFlag(value)
+		if err != nil {
+			log.Fatalf("Invalid capture: %v", err)
+		}
+		payloads, err := readPayloads(file)
+		if err != nil {
+			log.Fatalf("Failed to read capture: %v", err)
+		}
+		router.Subscriptions[subscription] = emulator.RouterSubscription{Payloads: payloads}
+	}
+
+	// Same subscription as the dial_in example
+	if len(router.Subscriptions) == 0 {
+		router.Subscriptions["lldp-dial-in-subs"] = emulator.RouterSubscription{Paths: []string{mdt.PathLLDPSummary}}
+	}
+
+	var opts []grpc.ServerOption
+	if len(*tlsCert) > 0 {
+		creds, err := credentials.NewServerTLSFromFile(*tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	address, err := router.Start(*listen, opts...)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	for subscription := range router.Subscriptions {
+		log.Printf("Serving subscription %s on %s", subscription, address)
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+	fmt.Printf("\nStopping the emulator\n")
+	router.Stop()
+}
@@ -5,191 +5,182 @@ Dial-in to specified router in specified subscription
 package main
 
 import (
-	"bytes"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/golang/protobuf/proto"
-	xr "github.com/nleiva/xrgrpc"
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dialin"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"github.com/CiscoSE/grpc_collector/output"
+)
+
+var (
+	paths      = flag.String("paths", "", "Comma separated sensor paths for an ad-hoc subscription, uses the pre-configured subscription if empty")
+	interval   = flag.Duration("interval", 5*time.Second, "Sample interval of the ad-hoc subscription")
+	record     = flag.String("record", "", "Record the raw telemetry payloads to this capture file")
+	deadLetter = flag.String("dead-letter", "", "Append the messages and rows that fail to decode to this file")
 
-	"github.com/nleiva/xrgrpc/proto/telemetry"
+	// Limits of the decoded messages, zero is unlimited
+	maxDepth       = flag.Int("max-depth", 32, "Maximum nesting of GPB-KV fields, deeper fields are dropped")
+	maxFields      = flag.Int("max-fields", 10000, "Maximum fields per measurement, further GPB-KV fields are dropped")
+	maxMessageSize = flag.Int("max-message-size", 4<<20, "Maximum size of a telemetry message in bytes, larger messages are rejected")
 )
 
 func main() {
+	flag.Parse()
 
 	// Determine the ID for first the transaction.
 	var id int64 = 1000
 
 	// Manually specify target parameters.
-	router1, err := xr.BuildRouter(
-		xr.WithUsername("YOURUSER"),        // e.g. admin
-		xr.WithPassword("YOUPASSWORD"),     // e.g. cisco123
-		xr.WithHost("DEVICEIP:DEVICEPORT"), // e.g. 192.168.0.1:57344
-		xr.WithTimeout(1000000),            // TODO, timeout shouldn't be required
-	)
-	if err != nil {
-		log.Fatalf("target parameters for router1 are incorrect: %s", err)
+	router1 := dialin.Router{
+		Username: "YOURUSER",            // e.g. admin
+		Password: "YOUPASSWORD",         // e.g. cisco123
+		Host:     "DEVICEIP:DEVICEPORT", // e.g. 192.168.0.1:57344
+		Timeout:  60 * time.Second,      // Only applies to the connection setup
 	}
 
+	ctx1, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// Connect to the targets
-	conn1, ctx1, err := xr.Connect(*router1)
+	client1, err := dialin.Dial(ctx1, router1)
 	if err != nil {
 		log.Fatalf("could not setup a client connection to %s, %v", router1.Host, err)
 	}
-	defer conn1.Close()
+	defer client1.Close()
+
+	// Open the capture file
+	if len(*record) > 0 {
+		client1.Recorder, err = capture.Create(*record)
+		if err != nil {
+			log.Fatalf("could not create capture file: %v", err)
+		}
+		defer client1.Recorder.Close()
+		log.Printf("Recording raw payloads to %s\n", *record)
+	}
+
+	// The compact types only tell which GPB-KV fields are lists
+	decoder := &mdt.Decoder{
+		Compact:        mdt.KnownCompact(),
+		Counters:       &mdt.Counters{},
+		MaxDepth:       *maxDepth,
+		MaxFields:      *maxFields,
+		MaxMessageSize: *maxMessageSize,
+	}
+
+	// Open the dead-letter file
+	if len(*deadLetter) > 0 {
+		decoder.DeadLetter, err = output.CreateDeadLetter(*deadLetter)
+		if err != nil {
+			log.Fatalf("could not create dead-letter file: %v", err)
+		}
+		defer decoder.DeadLetter.Close()
+		log.Printf("Writing rejected payloads to %s\n", *deadLetter)
+	}
 
-	ctx1, cancel := context.WithCancel(ctx1)
-	defer cancel()
 	c := make(chan os.Signal, 1)
 	// If no signals are provided, all incoming signals will be relayed to c.
-	// Otherwise, just the provided signals will. E.g.: signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	signal.Notify(c, os.Interrupt)
+	// Otherwise, just the provided signals will. SIGTERM is trapped too, so the
+	// ad-hoc subscription is removed from the router when the process is stopped.
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	defer func() {
 		signal.Stop(c)
 		cancel()
 	}()
 
-	// Telemetry Subscription
-	p := "Sub1" // Change as needed, this should be configured on the device in advance. See README file for instructions
-	// encoding KVGPB
-	var e int64 = 3
-	ch, ech, err := xr.GetSubscription(ctx1, conn1, p, id, e)
-	if err != nil {
-		log.Fatalf("could not setup Telemetry Subscription: %v\n", err)
-	}
-
 	go func() {
 		select {
 		case <-c:
 			fmt.Printf("\nmanually cancelled the session to %v\n\n", router1.Host)
 			cancel()
-			return
 		case <-ctx1.Done():
-			// Timeout: "context deadline exceeded"
-			err = ctx1.Err()
-			fmt.Printf("\ngRPC session timed out after %v seconds: %v\n\n", router1.Timeout, err.Error())
-			return
-		case err = <-ech:
-			// Session canceled: "context canceled"
-			fmt.Printf("\ngRPC session to %v failed: %v\n\n", router1.Host, err.Error())
-			return
 		}
 	}()
 
-	fmt.Printf("\nConnected to %s\n\n", router1.Host)
-	var namebuf bytes.Buffer
-	for tele := range ch {
-		log.Printf("***** New message from %v ***** \n", router1.Host)
-		message := new(telemetry.Telemetry)
-
-		err = proto.Unmarshal(tele, message)
-		if err != nil {
-			log.Printf("Could not unmarshall the interface telemetry message for %v: %v\n", router1.Host, err)
+	// Telemetry Subscription
+	p := "Sub1" // Change as needed, this should be configured on the device in advance. See README file for instructions
+	if len(*paths) > 0 {
+		// Push a temporary sensor-group and subscription for the requested paths
+		adhoc := dialin.AdHoc{
+			Name:     fmt.Sprintf("grpc-collector-%d", os.Getpid()),
+			Paths:    strings.Split(*paths, ","),
+			Interval: *interval,
 		}
-
-		for _, gpbkv := range message.DataGpbkv {
-			// Define file map
-			var fields map[string]interface{}
-
-			// Produce metadata tags
-			var tags map[string]string
-
-			// Top-level field may have measurement timestamp, if not use message timestamp
-			measured := gpbkv.Timestamp
-			if measured == 0 {
-				measured = message.MsgTimestamp
+		id++
+		if err = client1.Configure(ctx1, id, adhoc); err != nil {
+			log.Printf("could not configure ad-hoc subscription, falling back to gNMI: %v\n", err)
+			fmt.Printf("\ngNMI telemetry from %s\n\n", router1.Host)
+			if err := client1.StreamGNMI(ctx1, adhoc, os.Stdout); err != nil {
+				fmt.Printf("\ngNMI session to %v failed: %v\n\n", router1.Host, err.Error())
 			}
-
-			timestamp := time.Unix(int64(measured/1000), int64(measured%1000)*1000000)
-
-			// Populate tags and fields from toplevel GPBKV fields "keys" and "content"
-			for _, field := range gpbkv.Fields {
-				switch field.Name {
-				case "keys":
-					tags = make(map[string]string, len(field.Fields)+2)
-					tags["Producer"] = message.GetNodeIdStr()
-					tags["Target"] = message.GetSubscriptionIdStr()
-					tags["EncodingPath"] = message.EncodingPath
-					tags["TimeStamp"] = timestamp.String()
-					for _, subfield := range field.Fields {
-						parseGPBKVField(subfield, &namebuf, message.EncodingPath, timestamp, tags, nil)
-					}
-				case "content":
-					fields = make(map[string]interface{}, len(field.Fields))
-					for _, subfield := range field.Fields {
-						parseGPBKVField(subfield, &namebuf, message.EncodingPath, timestamp, tags, fields)
-					}
-				default:
-					log.Printf("I! Unexpected top-level MDT field: %s", field.Name)
-				}
+			return
+		}
+		log.Printf("Configured ad-hoc subscription %s on %s\n", adhoc.Name, router1.Host)
+		p = adhoc.Name
+
+		// The session context is already cancelled when this runs, clean up with a new one
+		defer func() {
+			cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cleanupCancel()
+			id++
+			if err := client1.Unconfigure(cleanupCtx, id, adhoc); err != nil {
+				log.Printf("could not remove ad-hoc subscription %s: %v\n", adhoc.Name, err)
+				return
 			}
+			log.Printf("Removed ad-hoc subscription %s from %s\n", adhoc.Name, router1.Host)
+		}()
+	}
 
-			// Print measurement
-			if len(fields) > 0 && len(tags) > 0 && len(message.EncodingPath) > 0 {
-
-				log.Printf("\n**** New Telemetry message from %v ****", tags["Producer"])
-				//log.Printf("Tags: %v", tags)
-				//log.Printf("Fields: %v\n", fields)
-				//log.Printf(telemetry.EncodingPath, fields, tags, timestamp)
-
-			} else {
-				fmt.Printf("I! Cisco MDT invalid field: encoding path or measurement empty")
-			}
-		}
+	fmt.Printf("\nConnected to %s\n\n", router1.Host)
+	id++
+	err = collect(ctx1, client1, dialin.Subscription{
+		ReqID:         id,
+		Encoding:      dialin.EncodingGPBKV,
+		Subscriptions: []string{p},
+	}, decoder, output.NewPrinter(os.Stdout))
+	if err != nil {
+		fmt.Printf("\ngRPC session to %v failed: %v\n\n", router1.Host, err.Error())
 	}
 
+	fmt.Printf("\nReceived telemetry:\n")
+	decoder.Counters.Print(os.Stdout)
 }
 
-// Recursively parse GPBKV field structure into fields or tags
-func parseGPBKVField(field *telemetry.TelemetryField, namebuf *bytes.Buffer,
-	path string, timestamp time.Time, tags map[string]string, fields map[string]interface{}) {
-
-	namelen := namebuf.Len()
-	if namelen > 0 {
-		namebuf.WriteRune('/')
-	}
-	namebuf.WriteString(field.Name)
-
-	// Decode Telemetry field value if set
-	var value interface{}
-	switch field.ValueByType.(type) {
-	case *telemetry.TelemetryField_BytesValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_BytesValue).BytesValue
-	case *telemetry.TelemetryField_StringValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_StringValue).StringValue
-	case *telemetry.TelemetryField_BoolValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_BoolValue).BoolValue
-	case *telemetry.TelemetryField_Uint32Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Uint32Value).Uint32Value
-	case *telemetry.TelemetryField_Uint64Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Uint64Value).Uint64Value
-	case *telemetry.TelemetryField_Sint32Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Sint32Value).Sint32Value
-	case *telemetry.TelemetryField_Sint64Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Sint64Value).Sint64Value
-	case *telemetry.TelemetryField_DoubleValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_DoubleValue).DoubleValue
-	case *telemetry.TelemetryField_FloatValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_FloatValue).FloatValue
+// Subscribe and write every decoded measurement to out until the stream ends.
+// The error ending the stream is returned, nil if it was closed by the router
+// or ctx. Device errors are reported as *dialin.DeviceError.
+func collect(ctx context.Context, client *dialin.Client, sub dialin.Subscription, decoder *mdt.Decoder, out output.Output) error {
+	ch, ech, err := client.Subscribe(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("could not setup Telemetry Subscription: %w", err)
 	}
+	defer out.Flush()
 
-	if value != nil {
-		// Distinguish between tags (keys) and fields (data) to write to
-		if fields != nil {
-			fields[namebuf.String()] = value
-		} else {
-			tags[namebuf.String()] = fmt.Sprint(value)
+	for tele := range ch {
+		log.Printf("***** New message from %v ***** \n", client.Router.Host)
+		metrics, err := decoder.Decode(tele)
+		if err != nil {
+			log.Printf("Could not unmarshall the interface telemetry message for %v: %v\n", client.Router.Host, err)
+		}
+		for _, metric := range metrics {
+			out.Write(metric)
 		}
-		fmt.Printf("%v: %v\n", namebuf.String(), value)
 	}
 
-	for _, subfield := range field.Fields {
-		parseGPBKVField(subfield, namebuf, path, timestamp, tags, fields)
+	select {
+	case err := <-ech:
+		if ctx.Err() == nil {
+			return err
+		}
+	default:
 	}
-
-	namebuf.Truncate(namelen)
+	return nil
 }
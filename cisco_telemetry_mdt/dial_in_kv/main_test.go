@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dialin"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"github.com/CiscoSE/grpc_collector/output"
+)
+
+const subscription = "Sub1"
+
+// Emulated router sending count samples of two LLDP neighbors
+func startRouter(t *testing.T, count int) string {
+	t.Helper()
+	router := &emulator.Router{
+		Name:     "router",
+		Username: "admin",
+		Password: "secret",
+		Subscriptions: map[string]emulator.RouterSubscription{
+			subscription: {Paths: []string{mdt.PathLLDPSummary}},
+		},
+		Rows:     2,
+		Interval: 10 * time.Millisecond,
+		Count:    count,
+	}
+	address, err := router.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(router.Stop)
+	return address
+}
+
+func dial(t *testing.T, address string, password string) *dialin.Client {
+	t.Helper()
+	client, err := dialin.Dial(context.Background(), dialin.Router{
+		Host:     address,
+		Username: "admin",
+		Password: password,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+var kvSub = dialin.Subscription{ReqID: 1, Encoding: dialin.EncodingGPBKV, Subscriptions: []string{subscription}}
+
+func TestCollect(t *testing.T) {
+	client := dial(t, startRouter(t, 3), "secret")
+	decoder := &mdt.Decoder{Compact: mdt.KnownCompact(), Counters: &mdt.Counters{}}
+	memory := &output.Memory{}
+	if err := collect(context.Background(), client, kvSub, decoder, memory); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := memory.Metrics()
+	if len(metrics) != 6 {
+		t.Fatalf("got %d metrics, want 6", len(metrics))
+	}
+	for _, metric := range metrics {
+		if metric.Name != mdt.PathLLDPSummary || metric.Tags["Producer"] != "router" ||
+			metric.Tags["interface_name"] == "" || metric.Fields["lldp_neighbor/0/chassis_id"] == nil {
+			t.Errorf("unexpected metric %v", metric)
+		}
+	}
+	want := mdt.Count{Messages: 3, Rows: 6}
+	if got := decoder.Counters.Counts()[mdt.CounterKey{Device: "router", Path: mdt.PathLLDPSummary}]; got != want {
+		t.Errorf("got count %+v, want %+v", got, want)
+	}
+}
+
+// Rejected credentials end the subscription with an authentication error
+func TestCollectAuth(t *testing.T) {
+	client := dial(t, startRouter(t, 1), "wrong")
+	memory := &output.Memory{}
+	err := collect(context.Background(), client, kvSub, &mdt.Decoder{}, memory)
+	if !errors.Is(err, dialin.ErrAuth) {
+		t.Errorf("got error %v, want %v", err, dialin.ErrAuth)
+	}
+	if memory.Len() != 0 {
+		t.Errorf("got %d metrics without authentication", memory.Len())
+	}
+}
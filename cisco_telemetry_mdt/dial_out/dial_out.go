@@ -1,36 +1,61 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
 	dialout "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt_dialout"
-	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
-	"github.com/golang/protobuf/proto"
+	"github.com/CiscoSE/grpc_collector/internal/grpcsize"
+	"github.com/CiscoSE/grpc_collector/output"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 var (
-	port = flag.Int("port", 10000, "The server port")
+	port       = flag.Int("port", 10000, "The server port")
+	record     = flag.String("record", "", "Record the raw telemetry payloads to this capture file")
+	deadLetter = flag.String("dead-letter", "", "Append the messages and rows that fail to decode to this file")
+	tlsCert    = flag.String("tls-cert", "", "TLS certificate file, plain text if empty")
+	tlsKey     = flag.String("tls-key", "", "TLS key file")
+
+	// Limits of the decoded messages, zero is unlimited
+	maxDepth       = flag.Int("max-depth", 32, "Maximum nesting of GPB-KV fields, deeper fields are dropped")
+	maxFields      = flag.Int("max-fields", 10000, "Maximum fields per measurement, further GPB-KV fields are dropped")
+	maxMessageSize = flag.Int("max-message-size", 4<<20, "Maximum size of a telemetry message in bytes, larger messages are rejected")
 )
 
 type DialOutServer struct {
 	cancel context.CancelFunc
 	ctx    context.Context
+
+	// Capture of the raw payloads, nothing is recorded if nil
+	recorder *capture.Writer
+
+	// Decoder and destination of the measurements
+	decoder *mdt.Decoder
+	output  output.Output
 }
 
 // MdtDialout RPC server method for grpc-dialout transport
 func (c *DialOutServer) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServer) error {
 	// Validate the context
 	peer, peerOK := peer.FromContext(stream.Context())
+	var address string
 	if peerOK {
+		address = peer.Addr.String()
 		log.Printf("Accepted Cisco MDT GRPC dialout connection from %s", peer.Addr)
 	}
 
@@ -38,12 +63,18 @@ func (c *DialOutServer) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServe
 		// Process every packet until EOF or until no more data
 		packet, err := stream.Recv()
 		if err != nil {
-			if err != io.EOF && c.ctx.Err() == nil {
+			if status.Code(err) == codes.ResourceExhausted {
+				// The message exceeds the receive size of the server, the stream is closed
+				log.Printf("E! Message from %s exceeds the maximum message size: %v", address, err)
+				c.decoder.Counters.Message("", "", err)
+			} else if err != io.EOF && c.ctx.Err() == nil {
 				fmt.Printf("E! GRPC dialout receive error: %v", err)
 			}
 			break
 		}
 
+		c.record(address, packet.Data)
+
 		if len(packet.Data) == 0 && len(packet.Errors) != 0 {
 			log.Printf("No more data")
 			break
@@ -59,125 +90,103 @@ func (c *DialOutServer) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServe
 	return nil
 }
 
+// Record a raw payload to the capture file
+func (c *DialOutServer) record(address string, data []byte) {
+	if c.recorder == nil || len(data) == 0 {
+		return
+	}
+	err := c.recorder.Write(&capture.Record{
+		Time:      time.Now(),
+		Transport: capture.MdtDialout,
+		Peer:      address,
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("E! Failed to record payload: %v", err)
+	}
+}
+
 // Handle telemetry packet from any transport, decode and add as measurement
 func (c *DialOutServer) handleTelemetry(data []byte) {
-	//
-	var namebuf bytes.Buffer
-	telemetry := &telemetry.Telemetry{}
-	// Unmarshal binary data into struct
-	err := proto.Unmarshal(data, telemetry)
+	metrics, err := c.decoder.Decode(data)
 	if err != nil {
 		log.Printf("Error: %s", err.Error())
-		return
 	}
 
-	for _, gpbkv := range telemetry.DataGpbkv {
-		// Define file map
-		var fields map[string]interface{}
-
-		// Produce metadata tags
-		var tags map[string]string
-
-		// Top-level field may have measurement timestamp, if not use message timestamp
-		measured := gpbkv.Timestamp
-		if measured == 0 {
-			measured = telemetry.MsgTimestamp
+	for _, metric := range metrics {
+		if err := c.output.Write(metric); err != nil {
+			log.Printf("E! Failed to write measurement: %v", err)
 		}
+	}
+}
 
-		timestamp := time.Unix(int64(measured/1000), int64(measured%1000)*1000000)
-
-		// Populate tags and fields from toplevel GPBKV fields "keys" and "content"
-		for _, field := range gpbkv.Fields {
-			switch field.Name {
-			case "keys":
-				tags = make(map[string]string, len(field.Fields)+2)
-				tags["Producer"] = telemetry.GetNodeIdStr()
-				tags["Target"] = telemetry.GetSubscriptionIdStr()
-				tags["EncodingPath"] = telemetry.EncodingPath
-				tags["TimeStamp"] = timestamp.String()
-				for _, subfield := range field.Fields {
-					c.parseGPBKVField(subfield, &namebuf, telemetry.EncodingPath, timestamp, tags, nil)
-				}
-			case "content":
-				fields = make(map[string]interface{}, len(field.Fields))
-				for _, subfield := range field.Fields {
-					c.parseGPBKVField(subfield, &namebuf, telemetry.EncodingPath, timestamp, tags, fields)
-				}
-			default:
-				log.Printf("I! Unexpected top-level MDT field: %s", field.Name)
-			}
-		}
-
-		// Print measurement
-		if len(fields) > 0 && len(tags) > 0 && len(telemetry.EncodingPath) > 0 {
-
-			log.Printf("\n**** New Telemetry message from %v ****", tags["Producer"])
-			log.Printf("Tags: %v", tags)
-			log.Printf("Fields: %v\n", fields)
-			//log.Printf(telemetry.EncodingPath, fields, tags, timestamp)
+// Serve dial-out connections on lis until the context of the server is done,
+// open streams are closed before returning. Payloads larger than the
+// MaxMessageSize of the decoder are rejected by the decoder, messages that do
+// not fit with their wrapper are rejected by gRPC before they are read.
+func (c *DialOutServer) Serve(lis net.Listener, opts ...grpc.ServerOption) error {
+	opts = append([]grpc.ServerOption{grpcsize.ServerOption(c.decoder.MaxMessageSize)}, opts...)
+	grpcServer := grpc.NewServer(opts...)
+	dialout.RegisterGRPCMdtDialoutServer(grpcServer, c)
 
-		} else {
-			fmt.Printf("I! Cisco MDT invalid field: encoding path or measurement empty")
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			grpcServer.Stop()
+		case <-done:
 		}
-	}
-
+	}()
+	return grpcServer.Serve(lis)
 }
 
-// Recursively parse GPBKV field structure into fields or tags
-func (c *DialOutServer) parseGPBKVField(field *telemetry.TelemetryField, namebuf *bytes.Buffer,
-	path string, timestamp time.Time, tags map[string]string, fields map[string]interface{}) {
+func main() {
+	flag.Parse()
 
-	namelen := namebuf.Len()
-	if namelen > 0 {
-		namebuf.WriteRune('/')
-	}
-	namebuf.WriteString(field.Name)
-
-	// Decode Telemetry field value if set
-	var value interface{}
-	switch field.ValueByType.(type) {
-	case *telemetry.TelemetryField_BytesValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_BytesValue).BytesValue
-	case *telemetry.TelemetryField_StringValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_StringValue).StringValue
-	case *telemetry.TelemetryField_BoolValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_BoolValue).BoolValue
-	case *telemetry.TelemetryField_Uint32Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Uint32Value).Uint32Value
-	case *telemetry.TelemetryField_Uint64Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Uint64Value).Uint64Value
-	case *telemetry.TelemetryField_Sint32Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Sint32Value).Sint32Value
-	case *telemetry.TelemetryField_Sint64Value:
-		value = field.ValueByType.(*telemetry.TelemetryField_Sint64Value).Sint64Value
-	case *telemetry.TelemetryField_DoubleValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_DoubleValue).DoubleValue
-	case *telemetry.TelemetryField_FloatValue:
-		value = field.ValueByType.(*telemetry.TelemetryField_FloatValue).FloatValue
+	// Create new server struct
+	c := &DialOutServer{
+		decoder: &mdt.Decoder{
+			Compact:        mdt.KnownCompact(),
+			Counters:       &mdt.Counters{},
+			MaxDepth:       *maxDepth,
+			MaxFields:      *maxFields,
+			MaxMessageSize: *maxMessageSize,
+		},
+		output: output.NewPrinter(os.Stdout),
 	}
 
-	if value != nil {
-		// Distinguish between tags (keys) and fields (data) to write to
-		if fields != nil {
-			fields[namebuf.String()] = value
-		} else {
-			tags[namebuf.String()] = fmt.Sprint(value)
+	// Open the dead-letter file
+	if len(*deadLetter) > 0 {
+		letters, err := output.CreateDeadLetter(*deadLetter)
+		if err != nil {
+			log.Fatalf("Failed to create dead-letter file: %v", err)
 		}
+		defer letters.Close()
+		c.decoder.DeadLetter = letters
+		log.Printf("Writing rejected payloads to %s", *deadLetter)
 	}
 
-	for _, subfield := range field.Fields {
-		c.parseGPBKVField(subfield, namebuf, path, timestamp, tags, fields)
+	// Open the capture file
+	if len(*record) > 0 {
+		recorder, err := capture.Create(*record)
+		if err != nil {
+			log.Fatalf("Failed to create capture file: %v", err)
+		}
+		defer recorder.Close()
+		c.recorder = recorder
+		log.Printf("Recording raw payloads to %s", *record)
 	}
 
-	namebuf.Truncate(namelen)
-}
-
-func main() {
-	// Create new server struct
-	c := &DialOutServer{}
-
-	// Add context
+	// Add context, cancelled on interrupt
 	c.ctx, c.cancel = context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		fmt.Printf("\nManually cancelled the server\n")
+		c.cancel()
+	}()
 
 	// Configure protocol and ports
 	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", *port))
@@ -186,15 +195,22 @@ func main() {
 		log.Fatalf("Failed to listen in configured port: %v", err)
 	}
 
-	// Create empty option
+	// Enable TLS if a certificate is given
 	var opts []grpc.ServerOption
-
-	// Create new gRPC server
-	grpcServer := grpc.NewServer(opts...)
-
-	// Register server to gRPC calls
-	dialout.RegisterGRPCMdtDialoutServer(grpcServer, c)
+	if len(*tlsCert) > 0 {
+		creds, err := credentials.NewServerTLSFromFile(*tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
 
 	// Start server
-	grpcServer.Serve(lis)
+	if err = c.Serve(lis, opts...); err != nil {
+		log.Printf("Server failed: %v", err)
+	}
+	c.output.Flush()
+
+	fmt.Printf("\nReceived telemetry:\n")
+	c.decoder.Counters.Print(os.Stdout)
 }
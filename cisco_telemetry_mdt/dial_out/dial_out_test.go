@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	dialout "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt_dialout"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
+	"github.com/CiscoSE/grpc_collector/internal/testcert"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Collector running in-process
+type testServer struct {
+	*DialOutServer
+	address string
+	memory  *output.Memory
+
+	// Closed when Serve returned with err
+	done chan struct{}
+	err  error
+	once sync.Once
+}
+
+// Start a dial-out server on address, use port 0 to pick a free port
+func startServer(t *testing.T, address string, opts ...grpc.ServerOption) *testServer {
+	t.Helper()
+	return startDecoder(t, address, &mdt.Decoder{Compact: mdt.KnownCompact()}, opts...)
+}
+
+// Start a dial-out server with its own decoder settings
+func startDecoder(t *testing.T, address string, decoder *mdt.Decoder, opts ...grpc.ServerOption) *testServer {
+	t.Helper()
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{
+		DialOutServer: &DialOutServer{decoder: decoder},
+		address:       lis.Addr().String(),
+		memory:        &output.Memory{},
+		done:          make(chan struct{}),
+	}
+	s.output = s.memory
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go func() {
+		defer close(s.done)
+		s.err = s.Serve(lis, opts...)
+	}()
+	t.Cleanup(s.stop)
+	return s
+}
+
+// Stop the server and wait for Serve to return, only the first call stops it
+func (s *testServer) stop() {
+	s.once.Do(func() {
+		s.cancel()
+		<-s.done
+	})
+}
+
+// Wait until the memory output holds at least count metrics
+func waitMetrics(t *testing.T, memory *output.Memory, count int) []*output.Metric {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for memory.Len() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d metrics, want %d", memory.Len(), count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return memory.Metrics()
+}
+
+// Check for a field by name, list entries in compact GPB are flattened with their index
+func hasField(metric *output.Metric, name string) bool {
+	for field := range metric.Fields {
+		if field == name || strings.HasSuffix(field, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDialOutEncodings(t *testing.T) {
+	for _, encoding := range []emulator.Encoding{emulator.GPB, emulator.GPBKV} {
+		server := startServer(t, "127.0.0.1:0")
+		devices := emulator.Devices(2, "device-", emulator.Device{
+			Subscription: "sub",
+			Address:      server.address,
+			Encoding:     encoding,
+			Rows:         3,
+			Interval:     10 * time.Millisecond,
+			Count:        2,
+		})
+		if err := emulator.RunDevices(context.Background(), devices); err != nil {
+			t.Fatal(err)
+		}
+
+		// Every device sends one message per path and sample
+		want := len(devices) * 2 * len(emulator.Paths()) * 3
+		metrics := waitMetrics(t, server.memory, want)
+		if len(metrics) != want {
+			t.Errorf("encoding %d: got %d metrics, want %d", encoding, len(metrics), want)
+		}
+
+		producers := make(map[string]int)
+		for _, metric := range metrics {
+			producers[metric.Tags["Producer"]]++
+			if metric.Tags["Target"] != "sub" || len(metric.Fields) == 0 {
+				t.Errorf("encoding %d: unexpected metric %v", encoding, metric)
+			}
+		}
+		if producers["device-1"] != want/2 || producers["device-2"] != want/2 {
+			t.Errorf("encoding %d: metrics per producer %v", encoding, producers)
+		}
+
+		lldp := 0
+		for _, metric := range metrics {
+			if metric.Name == mdt.PathLLDPSummary {
+				lldp++
+				if metric.Tags["interface_name"] == "" || !hasField(metric, "device_id") {
+					t.Errorf("encoding %d: LLDP metric without keys or content: %v", encoding, metric)
+				}
+			}
+		}
+		if lldp == 0 {
+			t.Errorf("encoding %d: no LLDP metrics", encoding)
+		}
+	}
+}
+
+func TestDialOutTLS(t *testing.T) {
+	files := testcert.Server(t)
+	server := startServer(t, "127.0.0.1:0", grpc.Creds(credentials.NewTLS(files.ServerConfig(t))))
+
+	device := &emulator.Device{
+		Name:        "tls-device",
+		Address:     server.address,
+		Paths:       []string{mdt.PathURIB},
+		Encoding:    emulator.GPB,
+		Rows:        2,
+		Count:       1,
+		DialOptions: []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(files.ClientConfig(t)))},
+	}
+	if err := device.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	metrics := waitMetrics(t, server.memory, 2)
+	if metrics[0].Tags["Producer"] != "tls-device" || metrics[0].Fields["address"] != "10.0.0.0" {
+		t.Errorf("unexpected metric %v", metrics[0])
+	}
+}
+
+func TestDialOutMalformed(t *testing.T) {
+	server := startServer(t, "127.0.0.1:0")
+
+	// Malformed payloads and device errors must not stop the server
+	bad := &emulator.Device{
+		Name:      "bad-device",
+		Address:   server.address,
+		Encoding:  emulator.GPB,
+		Interval:  time.Millisecond,
+		Count:     20,
+		Malformed: 0.5,
+		ErrorRate: 0.2,
+		ErrorText: "emulated error",
+	}
+	if err := bad.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := bad.Stats(); stats.Malformed == 0 || stats.Errors == 0 {
+		t.Fatalf("no errors injected: %+v", stats)
+	}
+
+	server.memory.Reset()
+	good := &emulator.Device{
+		Name:     "good-device",
+		Address:  server.address,
+		Paths:    []string{mdt.PathMac},
+		Encoding: emulator.GPBKV,
+		Rows:     4,
+		Count:    1,
+	}
+	if err := good.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	metrics := waitMetrics(t, server.memory, 4)
+	if metrics[0].Tags["Producer"] != "good-device" {
+		t.Errorf("unexpected metric %v", metrics[0])
+	}
+}
+
+func TestDialOutReconnect(t *testing.T) {
+	server := startServer(t, "127.0.0.1:0")
+	address := server.address
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	device := &emulator.Device{
+		Name:     "device",
+		Address:  address,
+		Paths:    []string{mdt.PathMac},
+		Encoding: emulator.GPB,
+		Interval: 10 * time.Millisecond,
+	}
+	done := make(chan error, 1)
+	go func() { done <- device.Run(ctx) }()
+	waitMetrics(t, server.memory, 1)
+
+	// The device keeps streaming to a restarted collector on the same address
+	server.stop()
+	restarted := startServer(t, address)
+	waitMetrics(t, restarted.memory, 1)
+	if device.Stats().Redials == 0 {
+		t.Errorf("device did not redial")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDialOutShutdown(t *testing.T) {
+	server := startServer(t, "127.0.0.1:0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	device := &emulator.Device{
+		Name:     "device",
+		Address:  server.address,
+		Paths:    []string{mdt.PathMac},
+		Encoding: emulator.GPB,
+		Interval: 10 * time.Millisecond,
+	}
+	go device.Run(ctx)
+	waitMetrics(t, server.memory, 1)
+
+	// Serve returns without error once the server is cancelled, even with open streams
+	server.cancel()
+	select {
+	case <-server.done:
+		if server.err != nil {
+			t.Fatalf("Serve failed: %v", server.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+}
+
+func TestDialOutLimits(t *testing.T) {
+	server := startDecoder(t, "127.0.0.1:0", &mdt.Decoder{
+		Counters:       &mdt.Counters{},
+		MaxFields:      3,
+		MaxMessageSize: 2048,
+	})
+
+	// Fields beyond the limit are dropped and the entries counted as truncated
+	device := &emulator.Device{
+		Name:     "device",
+		Address:  server.address,
+		Paths:    []string{mdt.PathURIB},
+		Encoding: emulator.GPBKV,
+		Rows:     2,
+		Count:    1,
+	}
+	if err := device.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, metric := range waitMetrics(t, server.memory, 2) {
+		if len(metric.Fields) != 3 {
+			t.Errorf("got %d fields, want 3", len(metric.Fields))
+		}
+	}
+	count := server.decoder.Counters.Counts()[mdt.CounterKey{Device: "device", Path: mdt.PathURIB}]
+	if count.Rows != 2 || count.Truncated != 2 {
+		t.Errorf("unexpected count %+v", count)
+	}
+
+	// A message over the size limit is rejected by gRPC
+	device.Rows = 50
+	device.Run(context.Background())
+	deadline := time.Now().Add(5 * time.Second)
+	for server.decoder.Counters.Counts()[mdt.CounterKey{}].Errors == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("oversized message not reported")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if server.memory.Len() != 2 {
+		t.Errorf("got %d metrics, want 2", server.memory.Len())
+	}
+}
+
+// Serialized GPB-KV message of exactly size bytes
+func sizedMessage(t *testing.T, size int) []byte {
+	t.Helper()
+	message := &telemetry.Telemetry{
+		NodeId:       &telemetry.Telemetry_NodeIdStr{NodeIdStr: "device"},
+		EncodingPath: "path",
+		DataGpbkv: []*telemetry.TelemetryField{{
+			Fields: []*telemetry.TelemetryField{
+				{Name: "keys", Fields: []*telemetry.TelemetryField{{Name: "id", ValueByType: &telemetry.TelemetryField_Uint64Value{Uint64Value: 1}}}},
+				{Name: "content", Fields: []*telemetry.TelemetryField{{Name: "a", ValueByType: &telemetry.TelemetryField_Uint64Value{Uint64Value: 1}}}},
+			},
+		}},
+	}
+	for pad := 0; ; {
+		message.Subscription = &telemetry.Telemetry_SubscriptionIdStr{SubscriptionIdStr: strings.Repeat("x", pad)}
+		data, err := proto.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) == size {
+			return data
+		}
+		pad += size - len(data)
+	}
+}
+
+// A payload of exactly the size limit reaches the decoder despite its gRPC
+// wrapper, a larger payload is rejected by the decoder and the stream goes on
+func TestDialOutMessageSize(t *testing.T) {
+	const limit = 2048
+	server := startDecoder(t, "127.0.0.1:0", &mdt.Decoder{Counters: &mdt.Counters{}, MaxMessageSize: limit})
+
+	conn, err := grpc.Dial(server.address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	stream, err := dialout.NewGRPCMdtDialoutClient(conn).MdtDialout(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, size := range []int{limit, limit + 1, limit} {
+		if err := stream.Send(&dialout.MdtDialoutArgs{ReqId: int64(i), Data: sizedMessage(t, size)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("stream ended with %v", err)
+	}
+
+	waitMetrics(t, server.memory, 2)
+	if got := server.decoder.Counters.Counts()[mdt.CounterKey{}]; got.Messages != 1 || got.Errors != 1 {
+		t.Errorf("got count %+v, want one rejected message", got)
+	}
+}
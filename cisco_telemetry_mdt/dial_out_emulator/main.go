@@ -0,0 +1,83 @@
+/*
+Emulate devices streaming model driven telemetry to a gRPC dial-out collector
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+)
+
+var (
+	target    = flag.String("target", "127.0.0.1:10000", "Dial-out collector as host:port")
+	devices   = flag.Int("devices", 1, "Number of emulated devices")
+	prefix    = flag.String("prefix", "device-", "Name prefix of the emulated devices")
+	paths     = flag.String("paths", "", "Comma separated encoding paths to stream, all supported paths if empty")
+	encoding  = flag.String("encoding", "gpbkv", "Encoding of the messages, gpb or gpbkv")
+	rows      = flag.Int("rows", 10, "Rows per message")
+	interval  = flag.Duration("interval", time.Second, "Interval between samples of every device")
+	count     = flag.Int("count", 0, "Number of samples per device, zero streams until interrupted")
+	malformed = flag.Float64("malformed", 0, "Fraction of messages sent malformed")
+	errorRate = flag.Float64("error-rate", 0, "Fraction of messages replaced by a device error")
+	errorText = flag.String("error-text", "emulated device error", "Text of the device errors")
+)
+
+func main() {
+	flag.Parse()
+
+	enc, err := emulator.ParseEncoding(*encoding)
+	if err != nil {
+		log.Fatalf("Invalid encoding: %v", err)
+	}
+	template := emulator.Device{
+		Subscription: "emulator",
+		Address:      *target,
+		Encoding:     enc,
+		Rows:         *rows,
+		Interval:     *interval,
+		Paths:        emulator.Paths(),
+		Count:        *count,
+		Malformed:    *malformed,
+		ErrorRate:    *errorRate,
+		ErrorText:    *errorText,
+	}
+	if len(*paths) > 0 {
+		template.Paths = strings.Split(*paths, ",")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-c:
+			fmt.Printf("\nManually cancelled the emulator\n")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	log.Printf("Streaming %s from %d devices to %s", strings.Join(template.Paths, ","), *devices, *target)
+	emulated := emulator.Devices(*devices, *prefix, template)
+	if err = emulator.RunDevices(ctx, emulated); err != nil {
+		log.Printf("Emulator failed: %v", err)
+	}
+
+	for _, device := range emulated {
+		stats := device.Stats()
+		log.Printf("%s: sent %d messages, %d malformed, %d errors, %d redials",
+			device.Name, stats.Sent, stats.Malformed, stats.Errors, stats.Redials)
+	}
+}
@@ -7,30 +7,49 @@ import (
 	"io"
 	"log"
 	"net"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/urib"
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
 	dialout "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt_dialout"
-	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
-	"github.com/golang/protobuf/proto"
+	"github.com/CiscoSE/grpc_collector/internal/grpcsize"
+	"github.com/CiscoSE/grpc_collector/output"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/peer"
 )
 
 var (
-	port = flag.Int("port", 10000, "The server port")
+	port       = flag.Int("port", 10000, "The server port")
+	record     = flag.String("record", "", "Record the raw telemetry payloads to this capture file")
+	deadLetter = flag.String("dead-letter", "", "Append the messages and rows that fail to decode to this file")
+
+	// Limits of the decoded messages, zero is unlimited
+	maxDepth       = flag.Int("max-depth", 32, "Maximum nesting of GPB-KV fields, deeper fields are dropped")
+	maxFields      = flag.Int("max-fields", 10000, "Maximum fields per measurement, further GPB-KV fields are dropped")
+	maxMessageSize = flag.Int("max-message-size", 4<<20, "Maximum size of a telemetry message in bytes, larger messages are rejected")
 )
 
 type DialOutServer struct {
 	cancel context.CancelFunc
 	ctx    context.Context
+
+	// Capture of the raw payloads, nothing is recorded if nil
+	recorder *capture.Writer
+
+	// Decoder of the compact GPB rows, with the accounting and dead-letter output
+	decoder *mdt.Decoder
 }
 
 // MdtDialout RPC server method for grpc-dialout transport
 func (c *DialOutServer) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServer) error {
 	// Validate the context
 	peer, peerOK := peer.FromContext(stream.Context())
+	var address string
 	if peerOK {
+		address = peer.Addr.String()
 		log.Printf("Accepted Cisco MDT GRPC dialout connection from %s", peer.Addr)
 	}
 
@@ -44,6 +63,8 @@ func (c *DialOutServer) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServe
 			break
 		}
 
+		c.record(address, packet.Data)
+
 		if len(packet.Data) == 0 && len(packet.Errors) != 0 {
 			log.Printf("No more data")
 			break
@@ -59,44 +80,79 @@ func (c *DialOutServer) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutServe
 	return nil
 }
 
-// Handle telemetry packet from any transport, decode and add as measurement
+// Record a raw payload to the capture file
+func (c *DialOutServer) record(address string, data []byte) {
+	if c.recorder == nil || len(data) == 0 {
+		return
+	}
+	err := c.recorder.Write(&capture.Record{
+		Time:      time.Now(),
+		Transport: capture.MdtDialout,
+		Peer:      address,
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("E! Failed to record payload: %v", err)
+	}
+}
+
+// Handle telemetry packet from any transport, decode and log the rows
 func (c *DialOutServer) handleTelemetry(data []byte) {
-	//
-	//var namebuf bytes.Buffer
-	message := &telemetry.Telemetry{}
-	// Unmarshal binary data into struct
-	err := proto.Unmarshal(data, message)
+	// Rows that fail to decode are skipped, counted and stored in the dead-letter file
+	metrics, err := c.decoder.Decode(data)
 	if err != nil {
 		log.Printf("Error: %s", err.Error())
-		return
 	}
-	log.Printf("***** New message from %v ***** \n", message.GetNodeId())
 
-	for _, row := range message.GetDataGpb().GetRow() {
+	for _, metric := range metrics {
+		log.Printf("***** New row from %v ***** \n", metric.Tags["Producer"])
+		log.Printf("Row timestamp: %v", metric.Timestamp)
+		log.Printf("Row content: %v", metric.Fields)
+		if address, ok := metric.Fields["address"]; ok {
+			log.Printf("Row address: %s", address)
+		}
+	}
+}
 
-		// Top-level field may have measurement timestamp
-		measured := row.GetTimestamp()
+func main() {
+	flag.Parse()
 
-		timestamp := time.Unix(int64(measured/1000), int64(measured%1000)*1000000)
-		content := row.GetContent()
-		routeL3 := new(urib.NxL3RouteProto)
+	// Rows of other encoding paths are decoded as URIB routes, like all rows before the known types
+	compact := mdt.KnownCompact()
+	compact[""] = compact[mdt.PathURIB]
 
-		err = proto.Unmarshal(content, routeL3)
+	// Create new server struct
+	c := &DialOutServer{decoder: &mdt.Decoder{
+		Compact:        compact,
+		Counters:       &mdt.Counters{},
+		MaxDepth:       *maxDepth,
+		MaxFields:      *maxFields,
+		MaxMessageSize: *maxMessageSize,
+	}}
+
+	// Open the capture file
+	if len(*record) > 0 {
+		recorder, err := capture.Create(*record)
 		if err != nil {
-			log.Fatalf("Could decode Content: %v\n", err)
+			log.Fatalf("Failed to create capture file: %v", err)
 		}
-
-		log.Printf("Row timestamp: %d", timestamp)
-		log.Printf("Row content: %v", routeL3)
-		log.Printf("Row address: %s", routeL3.GetAddress())
+		defer recorder.Close()
+		c.recorder = recorder
+		log.Printf("Recording raw payloads to %s", *record)
 	}
 
-}
-func main() {
-	// Create new server struct
-	c := &DialOutServer{}
+	// Open the dead-letter file
+	if len(*deadLetter) > 0 {
+		letters, err := output.CreateDeadLetter(*deadLetter)
+		if err != nil {
+			log.Fatalf("Failed to create dead-letter file: %v", err)
+		}
+		defer letters.Close()
+		c.decoder.DeadLetter = letters
+		log.Printf("Writing rejected payloads to %s", *deadLetter)
+	}
 
-	// Add context
+	// Add context, cancelled on interrupt
 	c.ctx, c.cancel = context.WithCancel(context.Background())
 
 	// Configure protocol and ports
@@ -106,8 +162,8 @@ func main() {
 		log.Fatalf("Failed to listen in configured port: %v", err)
 	}
 
-	// Create empty option
-	var opts []grpc.ServerOption
+	// Payloads over the limit are rejected by the decoder, gRPC allows 4 MiB by default
+	opts := []grpc.ServerOption{grpcsize.ServerOption(*maxMessageSize)}
 
 	// Create new gRPC server
 	grpcServer := grpc.NewServer(opts...)
@@ -115,7 +171,19 @@ func main() {
 	// Register server to gRPC calls
 	dialout.RegisterGRPCMdtDialoutServer(grpcServer, c)
 
+	// Stop the server on interrupt
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		fmt.Printf("\nManually cancelled the server\n")
+		c.cancel()
+		grpcServer.Stop()
+	}()
+
 	// Start server
 	grpcServer.Serve(lis)
 
+	fmt.Printf("\nReceived telemetry:\n")
+	c.decoder.Counters.Print(os.Stdout)
 }
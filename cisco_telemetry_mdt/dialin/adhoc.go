@@ -0,0 +1,279 @@
+package dialin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/ems"
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+	"github.com/CiscoSE/grpc_collector/internal/grpcerr"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// AdHoc describes a temporary sensor-group and subscription, pushed to the
+// router for the lifetime of a collection session
+type AdHoc struct {
+	// Identifier used for both the sensor-group and the subscription
+	Name string
+
+	// Sensor paths, e.g. Cisco-IOS-XR-ethernet-lldp-oper:lldp/nodes/node/neighbors/summaries/summary
+	Paths []string
+
+	// Sample interval, rounded to milliseconds
+	Interval time.Duration
+}
+
+// Cisco-IOS-XR-telemetry-model-driven-cfg structures, only the leaves we set
+type mdtConfig struct {
+	Telemetry mdtTelemetry `json:"Cisco-IOS-XR-telemetry-model-driven-cfg:telemetry-model-driven"`
+}
+
+type mdtTelemetry struct {
+	SensorGroups  mdtSensorGroups  `json:"sensor-groups"`
+	Subscriptions mdtSubscriptions `json:"subscriptions"`
+}
+
+type mdtSensorGroups struct {
+	SensorGroup []mdtSensorGroup `json:"sensor-group"`
+}
+
+type mdtSensorGroup struct {
+	ID          string          `json:"sensor-group-identifier"`
+	SensorPaths *mdtSensorPaths `json:"sensor-paths,omitempty"`
+}
+
+type mdtSensorPaths struct {
+	SensorPath []mdtSensorPath `json:"sensor-path"`
+}
+
+type mdtSensorPath struct {
+	Path string `json:"telemetry-sensor-path"`
+}
+
+type mdtSubscriptions struct {
+	Subscription []mdtSubscription `json:"subscription"`
+}
+
+type mdtSubscription struct {
+	ID             string             `json:"subscription-identifier"`
+	SensorProfiles *mdtSensorProfiles `json:"sensor-profiles,omitempty"`
+}
+
+type mdtSensorProfiles struct {
+	SensorProfile []mdtSensorProfile `json:"sensor-profile"`
+}
+
+type mdtSensorProfile struct {
+	SensorGroupID  string `json:"sensorgroupid"`
+	SampleInterval uint64 `json:"sample-interval"`
+}
+
+// Build the YANG JSON for the ad-hoc configuration, keys only if not full
+func (a AdHoc) yangJSON(full bool) (string, error) {
+	if len(a.Name) == 0 {
+		return "", fmt.Errorf("%w: ad-hoc subscription name is empty", ErrInvalid)
+	}
+
+	group := mdtSensorGroup{ID: a.Name}
+	sub := mdtSubscription{ID: a.Name}
+	if full {
+		if len(a.Paths) == 0 {
+			return "", fmt.Errorf("%w: no sensor path specified", ErrInvalid)
+		}
+		group.SensorPaths = &mdtSensorPaths{}
+		for _, path := range a.Paths {
+			group.SensorPaths.SensorPath = append(group.SensorPaths.SensorPath, mdtSensorPath{Path: path})
+		}
+		sub.SensorProfiles = &mdtSensorProfiles{
+			SensorProfile: []mdtSensorProfile{{
+				SensorGroupID:  a.Name,
+				SampleInterval: uint64(a.Interval / time.Millisecond),
+			}},
+		}
+	}
+
+	config := mdtConfig{
+		Telemetry: mdtTelemetry{
+			SensorGroups:  mdtSensorGroups{SensorGroup: []mdtSensorGroup{group}},
+			Subscriptions: mdtSubscriptions{Subscription: []mdtSubscription{sub}},
+		},
+	}
+	data, err := json.Marshal(config)
+	return string(data), err
+}
+
+// Configure pushes the ad-hoc sensor-group and subscription to the router
+func (c *Client) Configure(ctx context.Context, reqID int64, a AdHoc) error {
+	yangjson, err := a.yangJSON(true)
+	if err != nil {
+		return err
+	}
+
+	reply, err := c.oper.MergeConfig(ctx, &ems.ConfigArgs{ReqId: reqID, Yangjson: yangjson})
+	if err != nil {
+		return grpcerr.Classify(err)
+	}
+	if len(reply.GetErrors()) != 0 {
+		return &DeviceError{ReqID: reply.GetResReqId(), Message: reply.GetErrors()}
+	}
+	return nil
+}
+
+// Unconfigure removes the ad-hoc sensor-group and subscription from the router
+func (c *Client) Unconfigure(ctx context.Context, reqID int64, a AdHoc) error {
+	yangjson, err := a.yangJSON(false)
+	if err != nil {
+		return err
+	}
+
+	reply, err := c.oper.DeleteConfig(ctx, &ems.ConfigArgs{ReqId: reqID, Yangjson: yangjson})
+	if err != nil {
+		return grpcerr.Classify(err)
+	}
+	if len(reply.GetErrors()) != 0 {
+		return &DeviceError{ReqID: reply.GetResReqId(), Message: reply.GetErrors()}
+	}
+	return nil
+}
+
+// SubscribeGNMI streams the ad-hoc sensor paths over gNMI on the same
+// connection, for routers where the gRPC config service is not available.
+// Notifications are delivered on the returned channel until the stream ends.
+func (c *Client) SubscribeGNMI(ctx context.Context, a AdHoc) (<-chan *gnmi.Notification, <-chan error, error) {
+	if len(a.Paths) == 0 {
+		return nil, nil, fmt.Errorf("%w: no sensor path specified", ErrInvalid)
+	}
+
+	subscriptions := make([]*gnmi.Subscription, len(a.Paths))
+	for i, path := range a.Paths {
+		gnmiPath, err := sensorPath(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		subscriptions[i] = &gnmi.Subscription{
+			Path:           gnmiPath,
+			Mode:           gnmi.SubscriptionMode_SAMPLE,
+			SampleInterval: uint64(a.Interval.Nanoseconds()),
+		}
+	}
+
+	stream, err := gnmi.NewGNMIClient(c.conn).Subscribe(ctx)
+	if err != nil {
+		return nil, nil, grpcerr.Classify(err)
+	}
+	err = stream.Send(&gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Mode:         gnmi.SubscriptionList_STREAM,
+				Encoding:     gnmi.Encoding_PROTO,
+				Subscription: subscriptions,
+			},
+		},
+	})
+	if err != nil {
+		return nil, nil, grpcerr.Classify(err)
+	}
+
+	ch := make(chan *gnmi.Notification)
+	ech := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for {
+			reply, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				ech <- grpcerr.Classify(err)
+				return
+			}
+			if c.Recorder != nil {
+				if data, err := proto.Marshal(reply); err == nil {
+					c.record(capture.GNMI, data)
+				}
+			}
+			update := reply.GetUpdate()
+			if update == nil {
+				continue
+			}
+
+			select {
+			case ch <- update:
+			case <-ctx.Done():
+				ech <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return ch, ech, nil
+}
+
+// StreamGNMI streams the ad-hoc sensor paths over gNMI and prints every
+// received update to w as "path: value" until the stream ends. The error
+// ending the stream is returned, nil if it was closed by the router or ctx.
+func (c *Client) StreamGNMI(ctx context.Context, a AdHoc, w io.Writer) error {
+	ch, ech, err := c.SubscribeGNMI(ctx, a)
+	if err != nil {
+		return err
+	}
+
+	for notification := range ch {
+		log.Printf("***** New gNMI message from %v ***** \n", c.Router.Host)
+		for _, update := range notification.Update {
+			path := &gnmi.Path{
+				Origin: notification.GetPrefix().GetOrigin(),
+				Elem:   append(append([]*gnmi.PathElem{}, notification.GetPrefix().GetElem()...), update.GetPath().GetElem()...),
+			}
+			if len(path.Origin) == 0 {
+				path.Origin = update.GetPath().GetOrigin()
+			}
+			fmt.Fprintf(w, "%v: %v\n", gnmipath.Format(path), update.Val)
+		}
+	}
+
+	select {
+	case err := <-ech:
+		if ctx.Err() == nil {
+			return err
+		}
+	default:
+	}
+	return nil
+}
+
+// Convert a sensor path with a module prefix into a GNMI path, sensor paths
+// may omit the / after the prefix, e.g. Cisco-IOS-XR-ethernet-lldp-oper:lldp/nodes
+func sensorPath(path string) (*gnmi.Path, error) {
+	s := path
+	if i := strings.IndexAny(s, ":/["); i >= 0 && s[i] == ':' {
+		if !strings.HasPrefix(s[i+1:], "/") {
+			s = s[:i+1] + "/" + s[i+1:]
+		}
+	} else if !strings.HasPrefix(s, "/") {
+		s = "/" + s
+	}
+
+	gnmiPath, err := gnmipath.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if len(gnmiPath.Elem) == 0 {
+		return nil, fmt.Errorf("%w: invalid sensor path %s", ErrInvalid, path)
+	}
+
+	// Key values may be quoted in the router configuration
+	for _, elem := range gnmiPath.Elem {
+		for key, value := range elem.Key {
+			elem.Key[key] = strings.Trim(value, "'\"")
+		}
+	}
+	return gnmiPath, nil
+}
@@ -0,0 +1,39 @@
+package dialin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+)
+
+func TestSensorPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"Cisco-IOS-XR-ethernet-lldp-oper:lldp/nodes/node/neighbors/summaries/summary", "Cisco-IOS-XR-ethernet-lldp-oper:/lldp/nodes/node/neighbors/summaries/summary"},
+		{"Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface[interface-name=GigabitEthernet0/0/0/0]/latest/generic-counters",
+			"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface[interface-name=GigabitEthernet0/0/0/0]/latest/generic-counters"},
+		{`Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface[interface-name="GigabitEthernet0/0/0/1"]`,
+			"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface[interface-name=GigabitEthernet0/0/0/1]"},
+		{"openconfig-interfaces:/interfaces/interface[name=Ethernet1/1]/state", "openconfig-interfaces:/interfaces/interface[name=Ethernet1/1]/state"},
+		{"interfaces/interface", "/interfaces/interface"},
+	}
+	for _, test := range tests {
+		path, err := sensorPath(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if got := gnmipath.Format(path); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.path, got, test.want)
+		}
+	}
+
+	for _, path := range []string{"", "origin:", "lldp//nodes", "interface[name=Ethernet1/1"} {
+		if _, err := sensorPath(path); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: got error %v, want ErrInvalid", path, err)
+		}
+	}
+}
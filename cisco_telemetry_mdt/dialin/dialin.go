@@ -0,0 +1,221 @@
+/*
+Package dialin implements the IOS-XR gRPC dial-in client used to create
+model driven telemetry subscriptions on a router.
+*/
+
+package dialin
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/ems"
+	"github.com/CiscoSE/grpc_collector/internal/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Encodings accepted by CreateSubs
+const (
+	EncodingGPB   int64 = 2
+	EncodingGPBKV int64 = 3
+	EncodingJSON  int64 = 4
+)
+
+// Error classes returned by the client, check them with errors.Is
+var (
+	ErrAuth        = grpcerr.ErrAuth
+	ErrUnavailable = grpcerr.ErrUnavailable
+	ErrInvalid     = grpcerr.ErrInvalid
+)
+
+// DeviceError is an error reported by the router inside a reply message
+type DeviceError struct {
+	ReqID   int64
+	Message string
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("error triggered by remote host for ReqId %d: %s", e.ReqID, e.Message)
+}
+
+// Router holds the parameters to reach an IOS-XR device
+type Router struct {
+	Host     string
+	Username string
+	Password string
+
+	// TLS settings, plain text is used when nil
+	TLSConfig *tls.Config
+
+	// Timeout for the connection setup, zero waits forever
+	Timeout time.Duration
+}
+
+// Subscription parameters for CreateSubs
+type Subscription struct {
+	ReqID    int64
+	Encoding int64
+
+	// Names of the subscriptions configured on the device
+	Subscriptions []string
+
+	// DSCP marking of the telemetry stream, zero keeps the device default
+	QOS uint32
+}
+
+// Client for the IOS-XR gRPCConfigOper service
+type Client struct {
+	Router Router
+
+	// Capture of the raw telemetry payloads, nothing is recorded if nil
+	Recorder *capture.Writer
+
+	conn *grpc.ClientConn
+	oper ems.GRPCConfigOperClient
+}
+
+// Per RPC username and password metadata expected by IOS-XR
+type loginCreds struct {
+	username string
+	password string
+	secure   bool
+}
+
+func (c *loginCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		"username": c.username,
+		"password": c.password,
+	}, nil
+}
+
+func (c *loginCreds) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+// Dial connects to the router and waits until the connection is ready
+func Dial(ctx context.Context, router Router) (*Client, error) {
+	if len(router.Host) == 0 {
+		return nil, fmt.Errorf("%w: router host is empty", ErrInvalid)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithBlock(),
+		grpc.WithPerRPCCredentials(&loginCreds{
+			username: router.Username,
+			password: router.Password,
+			secure:   router.TLSConfig != nil,
+		}),
+	}
+	if router.TLSConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(router.TLSConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	if router.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, router.Timeout)
+		defer cancel()
+	}
+
+	conn, err := grpc.DialContext(ctx, router.Host, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not connect to %s: %v", ErrUnavailable, router.Host, err)
+	}
+
+	return &Client{
+		Router: router,
+		conn:   conn,
+		oper:   ems.NewGRPCConfigOperClient(conn),
+	}, nil
+}
+
+// Conn returns the underlying gRPC connection
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Close the connection to the router
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Subscribe to telemetry subscriptions configured on the router. Encoded
+// telemetry messages are delivered on the returned data channel, which is
+// closed when the stream ends. The reason is sent on the error channel,
+// nothing is sent if the stream was closed by the router without error.
+func (c *Client) Subscribe(ctx context.Context, sub Subscription) (<-chan []byte, <-chan error, error) {
+	if len(sub.Subscriptions) == 0 {
+		return nil, nil, fmt.Errorf("%w: no subscription specified", ErrInvalid)
+	}
+
+	args := &ems.CreateSubsArgs{
+		ReqId:         sub.ReqID,
+		Encode:        sub.Encoding,
+		Subidstr:      sub.Subscriptions[0],
+		Subscriptions: sub.Subscriptions,
+	}
+	if sub.QOS > 0 {
+		args.Qos = &ems.QOSMarking{Marking: sub.QOS}
+	}
+
+	stream, err := c.oper.CreateSubs(ctx, args)
+	if err != nil {
+		return nil, nil, grpcerr.Classify(err)
+	}
+
+	ch := make(chan []byte)
+	ech := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		for {
+			reply, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				ech <- grpcerr.Classify(err)
+				return
+			}
+			if len(reply.GetErrors()) != 0 {
+				ech <- &DeviceError{ReqID: reply.GetResReqId(), Message: reply.GetErrors()}
+				return
+			}
+			if len(reply.GetData()) == 0 {
+				continue
+			}
+			c.record(capture.MdtDialIn, reply.GetData())
+
+			select {
+			case ch <- reply.GetData():
+			case <-ctx.Done():
+				ech <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return ch, ech, nil
+}
+
+// Record a raw payload received from the router
+func (c *Client) record(transport capture.Transport, data []byte) {
+	if c.Recorder == nil {
+		return
+	}
+	err := c.Recorder.Write(&capture.Record{
+		Time:      time.Now(),
+		Transport: transport,
+		Peer:      c.Router.Host,
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("failed to record payload: %v", err)
+	}
+}
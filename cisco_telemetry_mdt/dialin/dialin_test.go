@@ -0,0 +1,269 @@
+package dialin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dialin"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"github.com/CiscoSE/grpc_collector/internal/testcert"
+	"github.com/CiscoSE/grpc_collector/output"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const subscription = "lldp-dial-in-subs"
+
+// Emulated router with the LLDP subscription configured
+func startRouter(t *testing.T, opts ...grpc.ServerOption) (*emulator.Router, string) {
+	t.Helper()
+	router := &emulator.Router{
+		Name:     "router",
+		Username: "admin",
+		Password: "secret",
+		Subscriptions: map[string]emulator.RouterSubscription{
+			subscription: {Paths: []string{mdt.PathLLDPSummary}},
+		},
+		Rows:     2,
+		Interval: 10 * time.Millisecond,
+	}
+	address, err := router.Start("127.0.0.1:0", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(router.Stop)
+	return router, address
+}
+
+func dial(t *testing.T, router dialin.Router) *dialin.Client {
+	t.Helper()
+	router.Timeout = 5 * time.Second
+	client, err := dialin.Dial(context.Background(), router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// Decode the payloads of a stream into memory until it ends, returns the number
+// of payloads that failed to decode and the stream error
+func collect(ch <-chan []byte, ech <-chan error, memory *output.Memory) (int, error) {
+	decoder := &mdt.Decoder{Compact: mdt.KnownCompact()}
+	failed := 0
+	for data := range ch {
+		metrics, err := decoder.Decode(data)
+		if err != nil {
+			failed++
+		}
+		for _, metric := range metrics {
+			memory.Write(metric)
+		}
+	}
+	select {
+	case err := <-ech:
+		return failed, err
+	default:
+		return failed, nil
+	}
+}
+
+func TestDialInEncodings(t *testing.T) {
+	router, address := startRouter(t)
+	router.Count = 3
+	client := dial(t, dialin.Router{Host: address, Username: "admin", Password: "secret"})
+
+	for _, encoding := range []int64{dialin.EncodingGPB, dialin.EncodingGPBKV} {
+		ch, ech, err := client.Subscribe(context.Background(), dialin.Subscription{
+			ReqID:         encoding,
+			Encoding:      encoding,
+			Subscriptions: []string{subscription},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		memory := &output.Memory{}
+		failed, err := collect(ch, ech, memory)
+		if err != nil || failed > 0 {
+			t.Fatalf("encoding %d: stream error %v, %d payloads failed", encoding, err, failed)
+		}
+		metrics := memory.Metrics()
+		if len(metrics) != 6 {
+			t.Fatalf("encoding %d: got %d metrics, want 6", encoding, len(metrics))
+		}
+		for _, metric := range metrics {
+			if metric.Name != mdt.PathLLDPSummary || metric.Tags["Producer"] != "router" ||
+				metric.Tags["Target"] != subscription || metric.Tags["interface_name"] == "" {
+				t.Errorf("encoding %d: unexpected metric %v", encoding, metric)
+			}
+		}
+	}
+}
+
+func TestDialInTLS(t *testing.T) {
+	files := testcert.Server(t)
+	router, address := startRouter(t, grpc.Creds(credentials.NewTLS(files.ServerConfig(t))))
+	router.Count = 1
+	client := dial(t, dialin.Router{
+		Host:      address,
+		Username:  "admin",
+		Password:  "secret",
+		TLSConfig: files.ClientConfig(t),
+	})
+
+	ch, ech, err := client.Subscribe(context.Background(), dialin.Subscription{
+		ReqID:         1,
+		Encoding:      dialin.EncodingGPBKV,
+		Subscriptions: []string{subscription},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := &output.Memory{}
+	if _, err := collect(ch, ech, memory); err != nil {
+		t.Fatal(err)
+	}
+	if memory.Len() != 2 {
+		t.Errorf("got %d metrics, want 2", memory.Len())
+	}
+}
+
+func TestDialInErrors(t *testing.T) {
+	_, address := startRouter(t)
+
+	client := dial(t, dialin.Router{Host: address, Username: "admin", Password: "wrong"})
+	ch, ech, err := client.Subscribe(context.Background(), dialin.Subscription{
+		ReqID:         1,
+		Encoding:      dialin.EncodingGPB,
+		Subscriptions: []string{subscription},
+	})
+	if err == nil {
+		_, err = collect(ch, ech, &output.Memory{})
+	}
+	if !errors.Is(err, dialin.ErrAuth) {
+		t.Errorf("wrong password: got %v, want ErrAuth", err)
+	}
+
+	client = dial(t, dialin.Router{Host: address, Username: "admin", Password: "secret"})
+	ch, ech, err = client.Subscribe(context.Background(), dialin.Subscription{
+		ReqID:         2,
+		Encoding:      dialin.EncodingGPB,
+		Subscriptions: []string{"unknown"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = collect(ch, ech, &output.Memory{})
+	var deviceErr *dialin.DeviceError
+	if !errors.As(err, &deviceErr) || deviceErr.ReqID != 2 {
+		t.Errorf("unknown subscription: got %v, want DeviceError", err)
+	}
+}
+
+func TestDialInMalformed(t *testing.T) {
+	router, address := startRouter(t)
+	router.Count = 20
+	router.Malformed = 0.5
+	client := dial(t, dialin.Router{Host: address, Username: "admin", Password: "secret"})
+
+	// Payloads that fail to decode do not end the stream
+	ch, ech, err := client.Subscribe(context.Background(), dialin.Subscription{
+		ReqID:         1,
+		Encoding:      dialin.EncodingGPB,
+		Subscriptions: []string{subscription},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := &output.Memory{}
+	failed, err := collect(ch, ech, memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed == 0 || failed == 20 {
+		t.Errorf("%d of 20 payloads failed to decode", failed)
+	}
+	if memory.Len() == 0 {
+		t.Errorf("no metrics decoded")
+	}
+}
+
+func TestDialInReconnect(t *testing.T) {
+	router, address := startRouter(t)
+	client := dial(t, dialin.Router{Host: address, Username: "admin", Password: "secret"})
+	sub := dialin.Subscription{
+		ReqID:         1,
+		Encoding:      dialin.EncodingGPBKV,
+		Subscriptions: []string{subscription},
+	}
+
+	ch, ech, err := client.Subscribe(context.Background(), sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	router.Drop()
+	for range ch {
+	}
+	if err := <-ech; !errors.Is(err, dialin.ErrUnavailable) {
+		t.Fatalf("dropped stream: got %v, want ErrUnavailable", err)
+	}
+
+	// The same client subscribes again once the router is back
+	router.Stop()
+	if _, err = router.Start(address); err != nil {
+		t.Fatal(err)
+	}
+	router.Count = 1
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		ch, ech, err = client.Subscribe(context.Background(), sub)
+		if err == nil {
+			memory := &output.Memory{}
+			if _, err = collect(ch, ech, memory); err == nil && memory.Len() == 2 {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("resubscribe failed: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if router.Connections() < 2 {
+		t.Errorf("got %d connections, want at least 2", router.Connections())
+	}
+}
+
+func TestDialInShutdown(t *testing.T) {
+	_, address := startRouter(t)
+	client := dial(t, dialin.Router{Host: address, Username: "admin", Password: "secret"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, _, err := client.Subscribe(ctx, dialin.Subscription{
+		ReqID:         1,
+		Encoding:      dialin.EncodingGPB,
+		Subscriptions: []string{subscription},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+
+	// Cancelling the subscription closes the data channel
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after cancel")
+	}
+}
@@ -0,0 +1,1552 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: ems_grpc.proto
+
+// Package implements the IOS-XR gRPC config and operational service
+
+package ems
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type CommitResult int32
+
+const (
+	CommitResult_CHANGE    CommitResult = 0
+	CommitResult_NO_CHANGE CommitResult = 1
+	CommitResult_FAIL      CommitResult = 2
+)
+
+var CommitResult_name = map[int32]string{
+	0: "CHANGE",
+	1: "NO_CHANGE",
+	2: "FAIL",
+}
+
+var CommitResult_value = map[string]int32{
+	"CHANGE":    0,
+	"NO_CHANGE": 1,
+	"FAIL":      2,
+}
+
+func (x CommitResult) String() string {
+	return proto.EnumName(CommitResult_name, int32(x))
+}
+
+func (CommitResult) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{0}
+}
+
+type ConfigGetArgs struct {
+	ReqId                int64    `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	Yangpathjson         string   `protobuf:"bytes,2,opt,name=yangpathjson,proto3" json:"yangpathjson,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfigGetArgs) Reset()         { *m = ConfigGetArgs{} }
+func (m *ConfigGetArgs) String() string { return proto.CompactTextString(m) }
+func (*ConfigGetArgs) ProtoMessage()    {}
+func (*ConfigGetArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{0}
+}
+
+func (m *ConfigGetArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigGetArgs.Unmarshal(m, b)
+}
+func (m *ConfigGetArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigGetArgs.Marshal(b, m, deterministic)
+}
+func (m *ConfigGetArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigGetArgs.Merge(m, src)
+}
+func (m *ConfigGetArgs) XXX_Size() int {
+	return xxx_messageInfo_ConfigGetArgs.Size(m)
+}
+func (m *ConfigGetArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigGetArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigGetArgs proto.InternalMessageInfo
+
+func (m *ConfigGetArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+func (m *ConfigGetArgs) GetYangpathjson() string {
+	if m != nil {
+		return m.Yangpathjson
+	}
+	return ""
+}
+
+type ConfigGetReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Yangjson             string   `protobuf:"bytes,2,opt,name=yangjson,proto3" json:"yangjson,omitempty"`
+	Errors               string   `protobuf:"bytes,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfigGetReply) Reset()         { *m = ConfigGetReply{} }
+func (m *ConfigGetReply) String() string { return proto.CompactTextString(m) }
+func (*ConfigGetReply) ProtoMessage()    {}
+func (*ConfigGetReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{1}
+}
+
+func (m *ConfigGetReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigGetReply.Unmarshal(m, b)
+}
+func (m *ConfigGetReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigGetReply.Marshal(b, m, deterministic)
+}
+func (m *ConfigGetReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigGetReply.Merge(m, src)
+}
+func (m *ConfigGetReply) XXX_Size() int {
+	return xxx_messageInfo_ConfigGetReply.Size(m)
+}
+func (m *ConfigGetReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigGetReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigGetReply proto.InternalMessageInfo
+
+func (m *ConfigGetReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *ConfigGetReply) GetYangjson() string {
+	if m != nil {
+		return m.Yangjson
+	}
+	return ""
+}
+
+func (m *ConfigGetReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+type GetOperArgs struct {
+	ReqId                int64    `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	Yangpathjson         string   `protobuf:"bytes,2,opt,name=yangpathjson,proto3" json:"yangpathjson,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOperArgs) Reset()         { *m = GetOperArgs{} }
+func (m *GetOperArgs) String() string { return proto.CompactTextString(m) }
+func (*GetOperArgs) ProtoMessage()    {}
+func (*GetOperArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{2}
+}
+
+func (m *GetOperArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetOperArgs.Unmarshal(m, b)
+}
+func (m *GetOperArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetOperArgs.Marshal(b, m, deterministic)
+}
+func (m *GetOperArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperArgs.Merge(m, src)
+}
+func (m *GetOperArgs) XXX_Size() int {
+	return xxx_messageInfo_GetOperArgs.Size(m)
+}
+func (m *GetOperArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperArgs proto.InternalMessageInfo
+
+func (m *GetOperArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+func (m *GetOperArgs) GetYangpathjson() string {
+	if m != nil {
+		return m.Yangpathjson
+	}
+	return ""
+}
+
+type GetOperReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Yangjson             string   `protobuf:"bytes,2,opt,name=yangjson,proto3" json:"yangjson,omitempty"`
+	Errors               string   `protobuf:"bytes,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetOperReply) Reset()         { *m = GetOperReply{} }
+func (m *GetOperReply) String() string { return proto.CompactTextString(m) }
+func (*GetOperReply) ProtoMessage()    {}
+func (*GetOperReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{3}
+}
+
+func (m *GetOperReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetOperReply.Unmarshal(m, b)
+}
+func (m *GetOperReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetOperReply.Marshal(b, m, deterministic)
+}
+func (m *GetOperReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetOperReply.Merge(m, src)
+}
+func (m *GetOperReply) XXX_Size() int {
+	return xxx_messageInfo_GetOperReply.Size(m)
+}
+func (m *GetOperReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetOperReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetOperReply proto.InternalMessageInfo
+
+func (m *GetOperReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *GetOperReply) GetYangjson() string {
+	if m != nil {
+		return m.Yangjson
+	}
+	return ""
+}
+
+func (m *GetOperReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+type ConfigArgs struct {
+	ReqId                int64    `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	Yangjson             string   `protobuf:"bytes,2,opt,name=yangjson,proto3" json:"yangjson,omitempty"`
+	Confirmed            bool     `protobuf:"varint,3,opt,name=Confirmed,proto3" json:"Confirmed,omitempty"`
+	ConfirmTimeout       uint32   `protobuf:"varint,4,opt,name=ConfirmTimeout,proto3" json:"ConfirmTimeout,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfigArgs) Reset()         { *m = ConfigArgs{} }
+func (m *ConfigArgs) String() string { return proto.CompactTextString(m) }
+func (*ConfigArgs) ProtoMessage()    {}
+func (*ConfigArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{4}
+}
+
+func (m *ConfigArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigArgs.Unmarshal(m, b)
+}
+func (m *ConfigArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigArgs.Marshal(b, m, deterministic)
+}
+func (m *ConfigArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigArgs.Merge(m, src)
+}
+func (m *ConfigArgs) XXX_Size() int {
+	return xxx_messageInfo_ConfigArgs.Size(m)
+}
+func (m *ConfigArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigArgs proto.InternalMessageInfo
+
+func (m *ConfigArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+func (m *ConfigArgs) GetYangjson() string {
+	if m != nil {
+		return m.Yangjson
+	}
+	return ""
+}
+
+func (m *ConfigArgs) GetConfirmed() bool {
+	if m != nil {
+		return m.Confirmed
+	}
+	return false
+}
+
+func (m *ConfigArgs) GetConfirmTimeout() uint32 {
+	if m != nil {
+		return m.ConfirmTimeout
+	}
+	return 0
+}
+
+type ConfigReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Errors               string   `protobuf:"bytes,2,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfigReply) Reset()         { *m = ConfigReply{} }
+func (m *ConfigReply) String() string { return proto.CompactTextString(m) }
+func (*ConfigReply) ProtoMessage()    {}
+func (*ConfigReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{5}
+}
+
+func (m *ConfigReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfigReply.Unmarshal(m, b)
+}
+func (m *ConfigReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfigReply.Marshal(b, m, deterministic)
+}
+func (m *ConfigReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfigReply.Merge(m, src)
+}
+func (m *ConfigReply) XXX_Size() int {
+	return xxx_messageInfo_ConfigReply.Size(m)
+}
+func (m *ConfigReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfigReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfigReply proto.InternalMessageInfo
+
+func (m *ConfigReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *ConfigReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+type CliConfigArgs struct {
+	ReqId                int64    `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	Cli                  string   `protobuf:"bytes,2,opt,name=cli,proto3" json:"cli,omitempty"`
+	Confirmed            bool     `protobuf:"varint,3,opt,name=Confirmed,proto3" json:"Confirmed,omitempty"`
+	ConfirmTimeout       uint32   `protobuf:"varint,4,opt,name=ConfirmTimeout,proto3" json:"ConfirmTimeout,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CliConfigArgs) Reset()         { *m = CliConfigArgs{} }
+func (m *CliConfigArgs) String() string { return proto.CompactTextString(m) }
+func (*CliConfigArgs) ProtoMessage()    {}
+func (*CliConfigArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{6}
+}
+
+func (m *CliConfigArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CliConfigArgs.Unmarshal(m, b)
+}
+func (m *CliConfigArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CliConfigArgs.Marshal(b, m, deterministic)
+}
+func (m *CliConfigArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CliConfigArgs.Merge(m, src)
+}
+func (m *CliConfigArgs) XXX_Size() int {
+	return xxx_messageInfo_CliConfigArgs.Size(m)
+}
+func (m *CliConfigArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_CliConfigArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CliConfigArgs proto.InternalMessageInfo
+
+func (m *CliConfigArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+func (m *CliConfigArgs) GetCli() string {
+	if m != nil {
+		return m.Cli
+	}
+	return ""
+}
+
+func (m *CliConfigArgs) GetConfirmed() bool {
+	if m != nil {
+		return m.Confirmed
+	}
+	return false
+}
+
+func (m *CliConfigArgs) GetConfirmTimeout() uint32 {
+	if m != nil {
+		return m.ConfirmTimeout
+	}
+	return 0
+}
+
+type CliConfigReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Errors               string   `protobuf:"bytes,2,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CliConfigReply) Reset()         { *m = CliConfigReply{} }
+func (m *CliConfigReply) String() string { return proto.CompactTextString(m) }
+func (*CliConfigReply) ProtoMessage()    {}
+func (*CliConfigReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{7}
+}
+
+func (m *CliConfigReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CliConfigReply.Unmarshal(m, b)
+}
+func (m *CliConfigReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CliConfigReply.Marshal(b, m, deterministic)
+}
+func (m *CliConfigReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CliConfigReply.Merge(m, src)
+}
+func (m *CliConfigReply) XXX_Size() int {
+	return xxx_messageInfo_CliConfigReply.Size(m)
+}
+func (m *CliConfigReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CliConfigReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CliConfigReply proto.InternalMessageInfo
+
+func (m *CliConfigReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *CliConfigReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+type CommitReplaceArgs struct {
+	ReqId                int64    `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	Cli                  string   `protobuf:"bytes,2,opt,name=cli,proto3" json:"cli,omitempty"`
+	Yangjson             string   `protobuf:"bytes,3,opt,name=yangjson,proto3" json:"yangjson,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommitReplaceArgs) Reset()         { *m = CommitReplaceArgs{} }
+func (m *CommitReplaceArgs) String() string { return proto.CompactTextString(m) }
+func (*CommitReplaceArgs) ProtoMessage()    {}
+func (*CommitReplaceArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{8}
+}
+
+func (m *CommitReplaceArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitReplaceArgs.Unmarshal(m, b)
+}
+func (m *CommitReplaceArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitReplaceArgs.Marshal(b, m, deterministic)
+}
+func (m *CommitReplaceArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitReplaceArgs.Merge(m, src)
+}
+func (m *CommitReplaceArgs) XXX_Size() int {
+	return xxx_messageInfo_CommitReplaceArgs.Size(m)
+}
+func (m *CommitReplaceArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitReplaceArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitReplaceArgs proto.InternalMessageInfo
+
+func (m *CommitReplaceArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+func (m *CommitReplaceArgs) GetCli() string {
+	if m != nil {
+		return m.Cli
+	}
+	return ""
+}
+
+func (m *CommitReplaceArgs) GetYangjson() string {
+	if m != nil {
+		return m.Yangjson
+	}
+	return ""
+}
+
+type CommitReplaceReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Errors               string   `protobuf:"bytes,2,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommitReplaceReply) Reset()         { *m = CommitReplaceReply{} }
+func (m *CommitReplaceReply) String() string { return proto.CompactTextString(m) }
+func (*CommitReplaceReply) ProtoMessage()    {}
+func (*CommitReplaceReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{9}
+}
+
+func (m *CommitReplaceReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitReplaceReply.Unmarshal(m, b)
+}
+func (m *CommitReplaceReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitReplaceReply.Marshal(b, m, deterministic)
+}
+func (m *CommitReplaceReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitReplaceReply.Merge(m, src)
+}
+func (m *CommitReplaceReply) XXX_Size() int {
+	return xxx_messageInfo_CommitReplaceReply.Size(m)
+}
+func (m *CommitReplaceReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitReplaceReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitReplaceReply proto.InternalMessageInfo
+
+func (m *CommitReplaceReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *CommitReplaceReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+type CommitMsg struct {
+	Label                string   `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	Comment              string   `protobuf:"bytes,2,opt,name=comment,proto3" json:"comment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommitMsg) Reset()         { *m = CommitMsg{} }
+func (m *CommitMsg) String() string { return proto.CompactTextString(m) }
+func (*CommitMsg) ProtoMessage()    {}
+func (*CommitMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{10}
+}
+
+func (m *CommitMsg) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitMsg.Unmarshal(m, b)
+}
+func (m *CommitMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitMsg.Marshal(b, m, deterministic)
+}
+func (m *CommitMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitMsg.Merge(m, src)
+}
+func (m *CommitMsg) XXX_Size() int {
+	return xxx_messageInfo_CommitMsg.Size(m)
+}
+func (m *CommitMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitMsg proto.InternalMessageInfo
+
+func (m *CommitMsg) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *CommitMsg) GetComment() string {
+	if m != nil {
+		return m.Comment
+	}
+	return ""
+}
+
+type CommitArgs struct {
+	Msg                  *CommitMsg `protobuf:"bytes,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	ReqId                int64      `protobuf:"varint,2,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *CommitArgs) Reset()         { *m = CommitArgs{} }
+func (m *CommitArgs) String() string { return proto.CompactTextString(m) }
+func (*CommitArgs) ProtoMessage()    {}
+func (*CommitArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{11}
+}
+
+func (m *CommitArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitArgs.Unmarshal(m, b)
+}
+func (m *CommitArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitArgs.Marshal(b, m, deterministic)
+}
+func (m *CommitArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitArgs.Merge(m, src)
+}
+func (m *CommitArgs) XXX_Size() int {
+	return xxx_messageInfo_CommitArgs.Size(m)
+}
+func (m *CommitArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitArgs proto.InternalMessageInfo
+
+func (m *CommitArgs) GetMsg() *CommitMsg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (m *CommitArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+type CommitReply struct {
+	Result               CommitResult `protobuf:"varint,1,opt,name=result,proto3,enum=IOSXRExtensibleManagabilityService.CommitResult" json:"result,omitempty"`
+	ResReqId             int64        `protobuf:"varint,2,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Errors               string       `protobuf:"bytes,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *CommitReply) Reset()         { *m = CommitReply{} }
+func (m *CommitReply) String() string { return proto.CompactTextString(m) }
+func (*CommitReply) ProtoMessage()    {}
+func (*CommitReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{12}
+}
+
+func (m *CommitReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommitReply.Unmarshal(m, b)
+}
+func (m *CommitReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommitReply.Marshal(b, m, deterministic)
+}
+func (m *CommitReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommitReply.Merge(m, src)
+}
+func (m *CommitReply) XXX_Size() int {
+	return xxx_messageInfo_CommitReply.Size(m)
+}
+func (m *CommitReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommitReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommitReply proto.InternalMessageInfo
+
+func (m *CommitReply) GetResult() CommitResult {
+	if m != nil {
+		return m.Result
+	}
+	return CommitResult_CHANGE
+}
+
+func (m *CommitReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *CommitReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+type DiscardChangesArgs struct {
+	ReqId                int64    `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DiscardChangesArgs) Reset()         { *m = DiscardChangesArgs{} }
+func (m *DiscardChangesArgs) String() string { return proto.CompactTextString(m) }
+func (*DiscardChangesArgs) ProtoMessage()    {}
+func (*DiscardChangesArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{13}
+}
+
+func (m *DiscardChangesArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiscardChangesArgs.Unmarshal(m, b)
+}
+func (m *DiscardChangesArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiscardChangesArgs.Marshal(b, m, deterministic)
+}
+func (m *DiscardChangesArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiscardChangesArgs.Merge(m, src)
+}
+func (m *DiscardChangesArgs) XXX_Size() int {
+	return xxx_messageInfo_DiscardChangesArgs.Size(m)
+}
+func (m *DiscardChangesArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiscardChangesArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiscardChangesArgs proto.InternalMessageInfo
+
+func (m *DiscardChangesArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+type DiscardChangesReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Errors               string   `protobuf:"bytes,2,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DiscardChangesReply) Reset()         { *m = DiscardChangesReply{} }
+func (m *DiscardChangesReply) String() string { return proto.CompactTextString(m) }
+func (*DiscardChangesReply) ProtoMessage()    {}
+func (*DiscardChangesReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{14}
+}
+
+func (m *DiscardChangesReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DiscardChangesReply.Unmarshal(m, b)
+}
+func (m *DiscardChangesReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DiscardChangesReply.Marshal(b, m, deterministic)
+}
+func (m *DiscardChangesReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DiscardChangesReply.Merge(m, src)
+}
+func (m *DiscardChangesReply) XXX_Size() int {
+	return xxx_messageInfo_DiscardChangesReply.Size(m)
+}
+func (m *DiscardChangesReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_DiscardChangesReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DiscardChangesReply proto.InternalMessageInfo
+
+func (m *DiscardChangesReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *DiscardChangesReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+// QOSMarking sets the DSCP value used by the device for the telemetry stream
+type QOSMarking struct {
+	Marking              uint32   `protobuf:"varint,1,opt,name=marking,proto3" json:"marking,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QOSMarking) Reset()         { *m = QOSMarking{} }
+func (m *QOSMarking) String() string { return proto.CompactTextString(m) }
+func (*QOSMarking) ProtoMessage()    {}
+func (*QOSMarking) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{15}
+}
+
+func (m *QOSMarking) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QOSMarking.Unmarshal(m, b)
+}
+func (m *QOSMarking) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QOSMarking.Marshal(b, m, deterministic)
+}
+func (m *QOSMarking) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QOSMarking.Merge(m, src)
+}
+func (m *QOSMarking) XXX_Size() int {
+	return xxx_messageInfo_QOSMarking.Size(m)
+}
+func (m *QOSMarking) XXX_DiscardUnknown() {
+	xxx_messageInfo_QOSMarking.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QOSMarking proto.InternalMessageInfo
+
+func (m *QOSMarking) GetMarking() uint32 {
+	if m != nil {
+		return m.Marking
+	}
+	return 0
+}
+
+// CreateSubsArgs selects the subscriptions and encoding to stream
+// encode: 2 = compact GPB, 3 = GPB-KV, 4 = JSON
+type CreateSubsArgs struct {
+	ReqId                int64       `protobuf:"varint,1,opt,name=ReqId,proto3" json:"ReqId,omitempty"`
+	Encode               int64       `protobuf:"varint,2,opt,name=encode,proto3" json:"encode,omitempty"`
+	Subidstr             string      `protobuf:"bytes,3,opt,name=subidstr,proto3" json:"subidstr,omitempty"`
+	Qos                  *QOSMarking `protobuf:"bytes,4,opt,name=qos,proto3" json:"qos,omitempty"`
+	Subscriptions        []string    `protobuf:"bytes,5,rep,name=Subscriptions,proto3" json:"Subscriptions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *CreateSubsArgs) Reset()         { *m = CreateSubsArgs{} }
+func (m *CreateSubsArgs) String() string { return proto.CompactTextString(m) }
+func (*CreateSubsArgs) ProtoMessage()    {}
+func (*CreateSubsArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{16}
+}
+
+func (m *CreateSubsArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateSubsArgs.Unmarshal(m, b)
+}
+func (m *CreateSubsArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateSubsArgs.Marshal(b, m, deterministic)
+}
+func (m *CreateSubsArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateSubsArgs.Merge(m, src)
+}
+func (m *CreateSubsArgs) XXX_Size() int {
+	return xxx_messageInfo_CreateSubsArgs.Size(m)
+}
+func (m *CreateSubsArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateSubsArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateSubsArgs proto.InternalMessageInfo
+
+func (m *CreateSubsArgs) GetReqId() int64 {
+	if m != nil {
+		return m.ReqId
+	}
+	return 0
+}
+
+func (m *CreateSubsArgs) GetEncode() int64 {
+	if m != nil {
+		return m.Encode
+	}
+	return 0
+}
+
+func (m *CreateSubsArgs) GetSubidstr() string {
+	if m != nil {
+		return m.Subidstr
+	}
+	return ""
+}
+
+func (m *CreateSubsArgs) GetQos() *QOSMarking {
+	if m != nil {
+		return m.Qos
+	}
+	return nil
+}
+
+func (m *CreateSubsArgs) GetSubscriptions() []string {
+	if m != nil {
+		return m.Subscriptions
+	}
+	return nil
+}
+
+// CreateSubsReply carries the encoded telemetry message in data
+type CreateSubsReply struct {
+	ResReqId             int64    `protobuf:"varint,1,opt,name=ResReqId,proto3" json:"ResReqId,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Errors               string   `protobuf:"bytes,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateSubsReply) Reset()         { *m = CreateSubsReply{} }
+func (m *CreateSubsReply) String() string { return proto.CompactTextString(m) }
+func (*CreateSubsReply) ProtoMessage()    {}
+func (*CreateSubsReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_be072c4f8622d341, []int{17}
+}
+
+func (m *CreateSubsReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateSubsReply.Unmarshal(m, b)
+}
+func (m *CreateSubsReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateSubsReply.Marshal(b, m, deterministic)
+}
+func (m *CreateSubsReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateSubsReply.Merge(m, src)
+}
+func (m *CreateSubsReply) XXX_Size() int {
+	return xxx_messageInfo_CreateSubsReply.Size(m)
+}
+func (m *CreateSubsReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateSubsReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateSubsReply proto.InternalMessageInfo
+
+func (m *CreateSubsReply) GetResReqId() int64 {
+	if m != nil {
+		return m.ResReqId
+	}
+	return 0
+}
+
+func (m *CreateSubsReply) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *CreateSubsReply) GetErrors() string {
+	if m != nil {
+		return m.Errors
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("IOSXRExtensibleManagabilityService.CommitResult", CommitResult_name, CommitResult_value)
+	proto.RegisterType((*ConfigGetArgs)(nil), "IOSXRExtensibleManagabilityService.ConfigGetArgs")
+	proto.RegisterType((*ConfigGetReply)(nil), "IOSXRExtensibleManagabilityService.ConfigGetReply")
+	proto.RegisterType((*GetOperArgs)(nil), "IOSXRExtensibleManagabilityService.GetOperArgs")
+	proto.RegisterType((*GetOperReply)(nil), "IOSXRExtensibleManagabilityService.GetOperReply")
+	proto.RegisterType((*ConfigArgs)(nil), "IOSXRExtensibleManagabilityService.ConfigArgs")
+	proto.RegisterType((*ConfigReply)(nil), "IOSXRExtensibleManagabilityService.ConfigReply")
+	proto.RegisterType((*CliConfigArgs)(nil), "IOSXRExtensibleManagabilityService.CliConfigArgs")
+	proto.RegisterType((*CliConfigReply)(nil), "IOSXRExtensibleManagabilityService.CliConfigReply")
+	proto.RegisterType((*CommitReplaceArgs)(nil), "IOSXRExtensibleManagabilityService.CommitReplaceArgs")
+	proto.RegisterType((*CommitReplaceReply)(nil), "IOSXRExtensibleManagabilityService.CommitReplaceReply")
+	proto.RegisterType((*CommitMsg)(nil), "IOSXRExtensibleManagabilityService.CommitMsg")
+	proto.RegisterType((*CommitArgs)(nil), "IOSXRExtensibleManagabilityService.CommitArgs")
+	proto.RegisterType((*CommitReply)(nil), "IOSXRExtensibleManagabilityService.CommitReply")
+	proto.RegisterType((*DiscardChangesArgs)(nil), "IOSXRExtensibleManagabilityService.DiscardChangesArgs")
+	proto.RegisterType((*DiscardChangesReply)(nil), "IOSXRExtensibleManagabilityService.DiscardChangesReply")
+	proto.RegisterType((*QOSMarking)(nil), "IOSXRExtensibleManagabilityService.QOSMarking")
+	proto.RegisterType((*CreateSubsArgs)(nil), "IOSXRExtensibleManagabilityService.CreateSubsArgs")
+	proto.RegisterType((*CreateSubsReply)(nil), "IOSXRExtensibleManagabilityService.CreateSubsReply")
+}
+
+func init() { proto.RegisterFile("ems_grpc.proto", fileDescriptor_be072c4f8622d341) }
+
+var fileDescriptor_be072c4f8622d341 = []byte{
+	// 783 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0x4d, 0x6f, 0xdb, 0x46,
+	0x10, 0x35, 0x45, 0x5b, 0x36, 0x47, 0xa2, 0xea, 0x6e, 0x8d, 0x42, 0x20, 0x7a, 0x10, 0x88, 0xc2,
+	0x10, 0x0c, 0x54, 0x76, 0x65, 0xb4, 0x3d, 0xf4, 0xd0, 0x2a, 0xb2, 0x23, 0x0b, 0x88, 0xac, 0x64,
+	0x15, 0x20, 0x1f, 0x87, 0x38, 0x14, 0xb5, 0xa1, 0x99, 0xf0, 0xcb, 0xbb, 0x2b, 0x23, 0x3a, 0x04,
+	0xc8, 0x21, 0x97, 0x20, 0xff, 0x2a, 0x87, 0xfc, 0xae, 0x80, 0xcb, 0x0f, 0x91, 0x09, 0x6c, 0x91,
+	0x4e, 0xe2, 0x1b, 0x67, 0xb9, 0x6f, 0xde, 0x7b, 0x33, 0xcb, 0xe1, 0x42, 0x83, 0xb8, 0xec, 0xcc,
+	0xa2, 0x81, 0xd9, 0x09, 0xa8, 0xcf, 0x7d, 0xa4, 0x0f, 0xc7, 0x93, 0xc7, 0xf8, 0xf8, 0x35, 0x27,
+	0x1e, 0xb3, 0xa7, 0x0e, 0x19, 0x19, 0x9e, 0x61, 0x19, 0x53, 0xdb, 0xb1, 0xf9, 0x62, 0x42, 0xe8,
+	0xa5, 0x6d, 0x12, 0x7d, 0x08, 0x6a, 0xdf, 0xf7, 0x5e, 0xd8, 0xd6, 0x80, 0xf0, 0x1e, 0xb5, 0x18,
+	0xda, 0x81, 0x0d, 0x4c, 0x2e, 0x86, 0xb3, 0xa6, 0xd4, 0x92, 0xda, 0x32, 0x8e, 0x02, 0xa4, 0x43,
+	0x7d, 0x61, 0x78, 0x56, 0x60, 0xf0, 0xf3, 0x97, 0xcc, 0xf7, 0x9a, 0x95, 0x96, 0xd4, 0x56, 0x70,
+	0x6e, 0x4d, 0x7f, 0x0e, 0x8d, 0x34, 0x15, 0x26, 0x81, 0xb3, 0x40, 0x1a, 0x6c, 0x61, 0xc2, 0xb2,
+	0xe9, 0xd2, 0x38, 0x7c, 0x17, 0xa2, 0x33, 0xd9, 0xd2, 0x18, 0xfd, 0x0a, 0x55, 0x42, 0xa9, 0x4f,
+	0x59, 0x53, 0x16, 0x6f, 0xe2, 0x48, 0x1f, 0x40, 0x6d, 0x40, 0xf8, 0x38, 0x20, 0xf4, 0x1b, 0xa5,
+	0x3e, 0x83, 0x7a, 0x9c, 0xe8, 0xc7, 0x08, 0x7d, 0x27, 0x01, 0x44, 0xb5, 0xb8, 0x46, 0xe8, 0x75,
+	0x89, 0x7f, 0x03, 0x45, 0xe0, 0xa9, 0x4b, 0x66, 0x22, 0xf7, 0x16, 0x5e, 0x2e, 0xa0, 0xdd, 0xb8,
+	0xd2, 0xd4, 0x7d, 0x68, 0xbb, 0xc4, 0x9f, 0xf3, 0xe6, 0x7a, 0x4b, 0x6a, 0xab, 0xf8, 0x8b, 0x55,
+	0xbd, 0x07, 0xb5, 0x48, 0xc5, 0x6a, 0x97, 0x4b, 0x27, 0x95, 0x9c, 0x93, 0x37, 0xa0, 0xf6, 0x1d,
+	0x7b, 0xa5, 0x97, 0x6d, 0x90, 0x4d, 0xc7, 0x8e, 0xb1, 0xe1, 0xe3, 0x77, 0x72, 0x70, 0x04, 0x8d,
+	0x94, 0xfe, 0xe6, 0x26, 0x1e, 0xc1, 0xcf, 0x7d, 0xdf, 0x75, 0x6d, 0x71, 0x2c, 0x0d, 0x93, 0x94,
+	0x32, 0x92, 0x6d, 0x93, 0x9c, 0x6f, 0x93, 0x7e, 0x02, 0x28, 0x97, 0xf8, 0xe6, 0x12, 0xff, 0x05,
+	0x25, 0xca, 0x34, 0x62, 0x56, 0x28, 0xcd, 0x31, 0xa6, 0xc4, 0x11, 0x68, 0x05, 0x47, 0x01, 0x6a,
+	0xc2, 0xa6, 0xe9, 0xbb, 0x2e, 0xf1, 0x78, 0x8c, 0x4d, 0x42, 0xdd, 0x04, 0x88, 0xc0, 0xc2, 0xd8,
+	0x7f, 0x20, 0xbb, 0xcc, 0x12, 0xd8, 0x5a, 0xf7, 0x8f, 0xce, 0xea, 0x21, 0xd0, 0x49, 0x99, 0xb1,
+	0xec, 0x46, 0xf4, 0x91, 0xf8, 0x4a, 0xa6, 0x32, 0xfa, 0x07, 0x09, 0x6a, 0xd1, 0xc6, 0xc8, 0xe5,
+	0x09, 0x54, 0x29, 0x61, 0x73, 0x87, 0x0b, 0xa6, 0x46, 0xf7, 0xa0, 0x38, 0x13, 0x16, 0x38, 0x1c,
+	0xe3, 0x73, 0xf5, 0xaa, 0x5c, 0x59, 0xaf, 0xfc, 0x17, 0xb6, 0x07, 0xe8, 0xc8, 0x66, 0xa6, 0x41,
+	0x67, 0xfd, 0x73, 0xc3, 0xb3, 0x08, 0xbb, 0xba, 0xa7, 0xfa, 0x10, 0x7e, 0xc9, 0xef, 0xbd, 0x79,
+	0x9b, 0x76, 0x01, 0x1e, 0x8c, 0x27, 0x23, 0x83, 0xbe, 0xb2, 0x3d, 0x2b, 0xec, 0x88, 0x1b, 0x3d,
+	0x8a, 0x04, 0x2a, 0x4e, 0x42, 0xfd, 0xa3, 0x04, 0x8d, 0x3e, 0x25, 0x06, 0x27, 0x93, 0xf9, 0xf4,
+	0x1a, 0x6d, 0x82, 0xc8, 0x33, 0xfd, 0x19, 0x89, 0x9d, 0xc7, 0x51, 0x28, 0x8e, 0xcd, 0xa7, 0xf6,
+	0x8c, 0x71, 0x9a, 0x9c, 0xba, 0x24, 0x46, 0xff, 0x83, 0x7c, 0xe1, 0x33, 0xf1, 0xc5, 0xd4, 0xba,
+	0x9d, 0x22, 0x65, 0x5f, 0x6a, 0xc6, 0x21, 0x14, 0xfd, 0x0e, 0x6a, 0xa8, 0xcb, 0xa4, 0x76, 0xc0,
+	0x6d, 0xdf, 0x63, 0xcd, 0x8d, 0x96, 0xdc, 0x56, 0x70, 0x7e, 0x51, 0x7f, 0x02, 0x3f, 0x2d, 0x3d,
+	0xac, 0xae, 0x19, 0x82, 0xf5, 0x99, 0xc1, 0x0d, 0x61, 0xa4, 0x8e, 0xc5, 0xf3, 0x55, 0xed, 0xdb,
+	0x3b, 0x84, 0x7a, 0xf6, 0x28, 0x20, 0x80, 0x6a, 0xff, 0xa4, 0x77, 0x3a, 0x38, 0xde, 0x5e, 0x43,
+	0x2a, 0x28, 0xa7, 0xe3, 0xb3, 0x38, 0x94, 0xd0, 0x16, 0xac, 0xdf, 0xed, 0x0d, 0xef, 0x6d, 0x57,
+	0xba, 0x9f, 0x14, 0x68, 0x58, 0xf8, 0x7e, 0x3f, 0x1a, 0x07, 0xe1, 0xf4, 0x46, 0x97, 0xa0, 0x0c,
+	0x08, 0x8f, 0x16, 0xd0, 0x9f, 0xc5, 0x4e, 0x60, 0xe6, 0x6f, 0xa7, 0x75, 0x4b, 0x41, 0x44, 0x0d,
+	0xf4, 0xb5, 0x03, 0x09, 0x05, 0x50, 0x1b, 0x11, 0x6a, 0x91, 0x98, 0xb9, 0x53, 0x3c, 0x8d, 0xa0,
+	0xdd, 0x2f, 0xbe, 0x3f, 0xe6, 0x44, 0x17, 0x50, 0x3f, 0x22, 0x0e, 0xe1, 0xb7, 0x48, 0x49, 0x41,
+	0x8d, 0xe7, 0xda, 0xed, 0x71, 0x72, 0x50, 0xd2, 0x81, 0x5f, 0xb0, 0xa1, 0xd9, 0xdf, 0x93, 0xd6,
+	0x2d, 0x05, 0x49, 0x58, 0xdf, 0x4a, 0xa0, 0x26, 0xe7, 0x51, 0x18, 0x46, 0x7f, 0x95, 0x99, 0x66,
+	0xe9, 0x4f, 0x45, 0xfb, 0xbb, 0x34, 0x2c, 0xd3, 0xdf, 0x68, 0xbd, 0x5c, 0xad, 0x93, 0xa9, 0xaf,
+	0xed, 0x17, 0xdf, 0x9f, 0x50, 0xbe, 0x97, 0x60, 0x27, 0x62, 0xcb, 0x8f, 0x47, 0x54, 0xc8, 0xc5,
+	0xd7, 0xe3, 0x57, 0xfb, 0xa7, 0x3c, 0x2e, 0xd1, 0x12, 0xc0, 0x66, 0x7c, 0x23, 0x43, 0x85, 0x9c,
+	0x64, 0xee, 0x81, 0xda, 0x41, 0x09, 0xc0, 0xf2, 0x13, 0x5e, 0x00, 0x2c, 0xa7, 0x1b, 0x2a, 0x76,
+	0x6e, 0x72, 0x13, 0x5d, 0x3b, 0x2c, 0x87, 0x49, 0xa9, 0xef, 0x6c, 0x3c, 0x95, 0x89, 0xcb, 0xa6,
+	0x55, 0x71, 0x4d, 0x3f, 0xfc, 0x3c, 0x00, 0x90, 0x04, 0x40, 0xc9, 0xb8, 0x0b, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// GRPCConfigOperClient is the client API for GRPCConfigOper service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type GRPCConfigOperClient interface {
+	GetConfig(ctx context.Context, in *ConfigGetArgs, opts ...grpc.CallOption) (GRPCConfigOper_GetConfigClient, error)
+	MergeConfig(ctx context.Context, in *ConfigArgs, opts ...grpc.CallOption) (*ConfigReply, error)
+	DeleteConfig(ctx context.Context, in *ConfigArgs, opts ...grpc.CallOption) (*ConfigReply, error)
+	ReplaceConfig(ctx context.Context, in *ConfigArgs, opts ...grpc.CallOption) (*ConfigReply, error)
+	CliConfig(ctx context.Context, in *CliConfigArgs, opts ...grpc.CallOption) (*CliConfigReply, error)
+	CommitReplace(ctx context.Context, in *CommitReplaceArgs, opts ...grpc.CallOption) (*CommitReplaceReply, error)
+	CommitConfig(ctx context.Context, in *CommitArgs, opts ...grpc.CallOption) (*CommitReply, error)
+	ConfigDiscardChanges(ctx context.Context, in *DiscardChangesArgs, opts ...grpc.CallOption) (*DiscardChangesReply, error)
+	// GetOper only returns operational data
+	GetOper(ctx context.Context, in *GetOperArgs, opts ...grpc.CallOption) (GRPCConfigOper_GetOperClient, error)
+	// CreateSubs streams telemetry data for subscriptions configured on the device
+	CreateSubs(ctx context.Context, in *CreateSubsArgs, opts ...grpc.CallOption) (GRPCConfigOper_CreateSubsClient, error)
+}
+
+type gRPCConfigOperClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGRPCConfigOperClient(cc grpc.ClientConnInterface) GRPCConfigOperClient {
+	return &gRPCConfigOperClient{cc}
+}
+
+func (c *gRPCConfigOperClient) GetConfig(ctx context.Context, in *ConfigGetArgs, opts ...grpc.CallOption) (GRPCConfigOper_GetConfigClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_GRPCConfigOper_serviceDesc.Streams[0], "/IOSXRExtensibleManagabilityService.gRPCConfigOper/GetConfig", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gRPCConfigOperGetConfigClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GRPCConfigOper_GetConfigClient interface {
+	Recv() (*ConfigGetReply, error)
+	grpc.ClientStream
+}
+
+type gRPCConfigOperGetConfigClient struct {
+	grpc.ClientStream
+}
+
+func (x *gRPCConfigOperGetConfigClient) Recv() (*ConfigGetReply, error) {
+	m := new(ConfigGetReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gRPCConfigOperClient) MergeConfig(ctx context.Context, in *ConfigArgs, opts ...grpc.CallOption) (*ConfigReply, error) {
+	out := new(ConfigReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/MergeConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) DeleteConfig(ctx context.Context, in *ConfigArgs, opts ...grpc.CallOption) (*ConfigReply, error) {
+	out := new(ConfigReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/DeleteConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) ReplaceConfig(ctx context.Context, in *ConfigArgs, opts ...grpc.CallOption) (*ConfigReply, error) {
+	out := new(ConfigReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/ReplaceConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) CliConfig(ctx context.Context, in *CliConfigArgs, opts ...grpc.CallOption) (*CliConfigReply, error) {
+	out := new(CliConfigReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CliConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) CommitReplace(ctx context.Context, in *CommitReplaceArgs, opts ...grpc.CallOption) (*CommitReplaceReply, error) {
+	out := new(CommitReplaceReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CommitReplace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) CommitConfig(ctx context.Context, in *CommitArgs, opts ...grpc.CallOption) (*CommitReply, error) {
+	out := new(CommitReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CommitConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) ConfigDiscardChanges(ctx context.Context, in *DiscardChangesArgs, opts ...grpc.CallOption) (*DiscardChangesReply, error) {
+	out := new(DiscardChangesReply)
+	err := c.cc.Invoke(ctx, "/IOSXRExtensibleManagabilityService.gRPCConfigOper/ConfigDiscardChanges", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *gRPCConfigOperClient) GetOper(ctx context.Context, in *GetOperArgs, opts ...grpc.CallOption) (GRPCConfigOper_GetOperClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_GRPCConfigOper_serviceDesc.Streams[1], "/IOSXRExtensibleManagabilityService.gRPCConfigOper/GetOper", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gRPCConfigOperGetOperClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GRPCConfigOper_GetOperClient interface {
+	Recv() (*GetOperReply, error)
+	grpc.ClientStream
+}
+
+type gRPCConfigOperGetOperClient struct {
+	grpc.ClientStream
+}
+
+func (x *gRPCConfigOperGetOperClient) Recv() (*GetOperReply, error) {
+	m := new(GetOperReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *gRPCConfigOperClient) CreateSubs(ctx context.Context, in *CreateSubsArgs, opts ...grpc.CallOption) (GRPCConfigOper_CreateSubsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_GRPCConfigOper_serviceDesc.Streams[2], "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CreateSubs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gRPCConfigOperCreateSubsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GRPCConfigOper_CreateSubsClient interface {
+	Recv() (*CreateSubsReply, error)
+	grpc.ClientStream
+}
+
+type gRPCConfigOperCreateSubsClient struct {
+	grpc.ClientStream
+}
+
+func (x *gRPCConfigOperCreateSubsClient) Recv() (*CreateSubsReply, error) {
+	m := new(CreateSubsReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GRPCConfigOperServer is the server API for GRPCConfigOper service.
+type GRPCConfigOperServer interface {
+	GetConfig(*ConfigGetArgs, GRPCConfigOper_GetConfigServer) error
+	MergeConfig(context.Context, *ConfigArgs) (*ConfigReply, error)
+	DeleteConfig(context.Context, *ConfigArgs) (*ConfigReply, error)
+	ReplaceConfig(context.Context, *ConfigArgs) (*ConfigReply, error)
+	CliConfig(context.Context, *CliConfigArgs) (*CliConfigReply, error)
+	CommitReplace(context.Context, *CommitReplaceArgs) (*CommitReplaceReply, error)
+	CommitConfig(context.Context, *CommitArgs) (*CommitReply, error)
+	ConfigDiscardChanges(context.Context, *DiscardChangesArgs) (*DiscardChangesReply, error)
+	// GetOper only returns operational data
+	GetOper(*GetOperArgs, GRPCConfigOper_GetOperServer) error
+	// CreateSubs streams telemetry data for subscriptions configured on the device
+	CreateSubs(*CreateSubsArgs, GRPCConfigOper_CreateSubsServer) error
+}
+
+// UnimplementedGRPCConfigOperServer can be embedded to have forward compatible implementations.
+type UnimplementedGRPCConfigOperServer struct {
+}
+
+func (*UnimplementedGRPCConfigOperServer) GetConfig(req *ConfigGetArgs, srv GRPCConfigOper_GetConfigServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) MergeConfig(ctx context.Context, req *ConfigArgs) (*ConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeConfig not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) DeleteConfig(ctx context.Context, req *ConfigArgs) (*ConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteConfig not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) ReplaceConfig(ctx context.Context, req *ConfigArgs) (*ConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplaceConfig not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) CliConfig(ctx context.Context, req *CliConfigArgs) (*CliConfigReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CliConfig not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) CommitReplace(ctx context.Context, req *CommitReplaceArgs) (*CommitReplaceReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitReplace not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) CommitConfig(ctx context.Context, req *CommitArgs) (*CommitReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitConfig not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) ConfigDiscardChanges(ctx context.Context, req *DiscardChangesArgs) (*DiscardChangesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfigDiscardChanges not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) GetOper(req *GetOperArgs, srv GRPCConfigOper_GetOperServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetOper not implemented")
+}
+func (*UnimplementedGRPCConfigOperServer) CreateSubs(req *CreateSubsArgs, srv GRPCConfigOper_CreateSubsServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateSubs not implemented")
+}
+
+func RegisterGRPCConfigOperServer(s *grpc.Server, srv GRPCConfigOperServer) {
+	s.RegisterService(&_GRPCConfigOper_serviceDesc, srv)
+}
+
+func _GRPCConfigOper_GetConfig_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ConfigGetArgs)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GRPCConfigOperServer).GetConfig(m, &gRPCConfigOperGetConfigServer{stream})
+}
+
+type GRPCConfigOper_GetConfigServer interface {
+	Send(*ConfigGetReply) error
+	grpc.ServerStream
+}
+
+type gRPCConfigOperGetConfigServer struct {
+	grpc.ServerStream
+}
+
+func (x *gRPCConfigOperGetConfigServer) Send(m *ConfigGetReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GRPCConfigOper_MergeConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).MergeConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/MergeConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).MergeConfig(ctx, req.(*ConfigArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_DeleteConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).DeleteConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/DeleteConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).DeleteConfig(ctx, req.(*ConfigArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_ReplaceConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).ReplaceConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/ReplaceConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).ReplaceConfig(ctx, req.(*ConfigArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_CliConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CliConfigArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).CliConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CliConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).CliConfig(ctx, req.(*CliConfigArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_CommitReplace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitReplaceArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).CommitReplace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CommitReplace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).CommitReplace(ctx, req.(*CommitReplaceArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_CommitConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).CommitConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/CommitConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).CommitConfig(ctx, req.(*CommitArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_ConfigDiscardChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiscardChangesArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GRPCConfigOperServer).ConfigDiscardChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/IOSXRExtensibleManagabilityService.gRPCConfigOper/ConfigDiscardChanges",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GRPCConfigOperServer).ConfigDiscardChanges(ctx, req.(*DiscardChangesArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GRPCConfigOper_GetOper_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetOperArgs)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GRPCConfigOperServer).GetOper(m, &gRPCConfigOperGetOperServer{stream})
+}
+
+type GRPCConfigOper_GetOperServer interface {
+	Send(*GetOperReply) error
+	grpc.ServerStream
+}
+
+type gRPCConfigOperGetOperServer struct {
+	grpc.ServerStream
+}
+
+func (x *gRPCConfigOperGetOperServer) Send(m *GetOperReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _GRPCConfigOper_CreateSubs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CreateSubsArgs)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GRPCConfigOperServer).CreateSubs(m, &gRPCConfigOperCreateSubsServer{stream})
+}
+
+type GRPCConfigOper_CreateSubsServer interface {
+	Send(*CreateSubsReply) error
+	grpc.ServerStream
+}
+
+type gRPCConfigOperCreateSubsServer struct {
+	grpc.ServerStream
+}
+
+func (x *gRPCConfigOperCreateSubsServer) Send(m *CreateSubsReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _GRPCConfigOper_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "IOSXRExtensibleManagabilityService.gRPCConfigOper",
+	HandlerType: (*GRPCConfigOperServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MergeConfig",
+			Handler:    _GRPCConfigOper_MergeConfig_Handler,
+		},
+		{
+			MethodName: "DeleteConfig",
+			Handler:    _GRPCConfigOper_DeleteConfig_Handler,
+		},
+		{
+			MethodName: "ReplaceConfig",
+			Handler:    _GRPCConfigOper_ReplaceConfig_Handler,
+		},
+		{
+			MethodName: "CliConfig",
+			Handler:    _GRPCConfigOper_CliConfig_Handler,
+		},
+		{
+			MethodName: "CommitReplace",
+			Handler:    _GRPCConfigOper_CommitReplace_Handler,
+		},
+		{
+			MethodName: "CommitConfig",
+			Handler:    _GRPCConfigOper_CommitConfig_Handler,
+		},
+		{
+			MethodName: "ConfigDiscardChanges",
+			Handler:    _GRPCConfigOper_ConfigDiscardChanges_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetConfig",
+			Handler:       _GRPCConfigOper_GetConfig_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetOper",
+			Handler:       _GRPCConfigOper_GetOper_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "CreateSubs",
+			Handler:       _GRPCConfigOper_CreateSubs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ems_grpc.proto",
+}
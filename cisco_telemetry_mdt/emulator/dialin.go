@@ -0,0 +1,237 @@
+package emulator
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/ems"
+	"github.com/CiscoSE/grpc_collector/internal/grpcauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Encodings requested in CreateSubsArgs
+const (
+	encodeGPB   = 2
+	encodeGPBKV = 3
+)
+
+// RouterSubscription is a telemetry subscription configured on an emulated router
+type RouterSubscription struct {
+	// Encoding paths generated in the requested encoding, all supported paths if empty
+	Paths []string
+
+	// Canned telemetry messages sent in order instead of generated ones,
+	// repeated until the stream ends
+	Payloads [][]byte
+}
+
+// Router emulates the IOS-XR gRPCConfigOper service for dial-in collectors,
+// only CreateSubs is implemented
+type Router struct {
+	ems.UnimplementedGRPCConfigOperServer
+
+	// Node ID sent in the generated messages
+	Name string
+
+	// Credentials required in the request metadata, not checked if Username is empty
+	Username string
+	Password string
+
+	// Subscriptions configured on the router by name
+	Subscriptions map[string]RouterSubscription
+
+	// Rows per generated message
+	Rows int
+
+	// Interval between samples, one second if zero
+	Interval time.Duration
+
+	// Number of samples per stream, the router ends the stream afterwards,
+	// zero streams until the collector cancels
+	Count int
+
+	// Fraction of generated messages sent malformed, see Device
+	Malformed float64
+
+	// Fraction of samples replaced by a reply with ErrorText, which ends the stream
+	ErrorRate float64
+	ErrorText string
+
+	mu          sync.Mutex
+	server      *grpc.Server
+	drops       map[chan struct{}]struct{}
+	connections int
+}
+
+// Start serving on address and return the address listened on, use port 0 to pick a free port
+func (r *Router) Start(address string, opts ...grpc.ServerOption) (string, error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", err
+	}
+	server := grpc.NewServer(opts...)
+	ems.RegisterGRPCConfigOperServer(server, r)
+
+	r.mu.Lock()
+	r.server = server
+	r.mu.Unlock()
+
+	go server.Serve(lis)
+	return lis.Addr().String(), nil
+}
+
+// Stop the server and close all connections, the router can be started again
+func (r *Router) Stop() {
+	r.mu.Lock()
+	server := r.server
+	r.server = nil
+	r.mu.Unlock()
+
+	if server != nil {
+		server.Stop()
+	}
+}
+
+// Drop all active streams with codes.Unavailable, as if the connection was lost
+func (r *Router) Drop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for drop := range r.drops {
+		close(drop)
+		delete(r.drops, drop)
+	}
+}
+
+// Connections returns the number of streams accepted so far
+func (r *Router) Connections() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connections
+}
+
+// CreateSubs streams the telemetry of the first known subscription in the
+// request. Unknown subscriptions and encodings are reported in the reply
+// errors like a router does.
+func (r *Router) CreateSubs(args *ems.CreateSubsArgs, stream ems.GRPCConfigOper_CreateSubsServer) error {
+	if err := r.authenticate(stream); err != nil {
+		return err
+	}
+
+	names := args.Subscriptions
+	if len(names) == 0 && len(args.Subidstr) > 0 {
+		names = []string{args.Subidstr}
+	}
+	var name string
+	var sub RouterSubscription
+	for _, candidate := range names {
+		var ok bool
+		if sub, ok = r.Subscriptions[candidate]; ok {
+			name = candidate
+			break
+		}
+	}
+	if len(name) == 0 {
+		return stream.Send(&ems.CreateSubsReply{
+			ResReqId: args.ReqId,
+			Errors:   fmt.Sprintf("subscriptions %v not found", names),
+		})
+	}
+
+	var encoding Encoding
+	switch args.Encode {
+	case encodeGPB:
+		encoding = GPB
+	case encodeGPBKV:
+		encoding = GPBKV
+	default:
+		if len(sub.Payloads) == 0 {
+			return stream.Send(&ems.CreateSubsReply{
+				ResReqId: args.ReqId,
+				Errors:   fmt.Sprintf("encoding %d not supported", args.Encode),
+			})
+		}
+	}
+
+	paths := sub.Paths
+	if len(paths) == 0 {
+		paths = Paths()
+	}
+	generators := make([]*Generator, len(paths))
+	for i, path := range paths {
+		generators[i] = &Generator{Node: r.Name, Subscription: name, Path: path, Encoding: encoding, Rows: r.Rows}
+	}
+
+	drop := make(chan struct{})
+	r.mu.Lock()
+	if r.drops == nil {
+		r.drops = make(map[chan struct{}]struct{})
+	}
+	r.drops[drop] = struct{}{}
+	r.connections++
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.drops, drop)
+		r.mu.Unlock()
+	}()
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	next := 0
+
+	for sample := 0; r.Count == 0 || sample < r.Count; sample++ {
+		if sample > 0 {
+			select {
+			case <-stream.Context().Done():
+				return nil
+			case <-drop:
+				return status.Error(codes.Unavailable, "connection dropped by the emulator")
+			case <-ticker.C:
+			}
+		}
+
+		if r.ErrorRate > 0 && random.Float64() < r.ErrorRate {
+			return stream.Send(&ems.CreateSubsReply{ResReqId: args.ReqId, Errors: r.ErrorText})
+		}
+
+		var payloads [][]byte
+		if len(sub.Payloads) > 0 {
+			payloads = append(payloads, sub.Payloads[next%len(sub.Payloads)])
+			next++
+		} else {
+			for _, generator := range generators {
+				message, err := generator.Next(time.Now())
+				if err != nil {
+					return status.Error(codes.Internal, err.Error())
+				}
+				data, err := encode(message, r.Malformed > 0 && random.Float64() < r.Malformed)
+				if err != nil {
+					return status.Error(codes.Internal, err.Error())
+				}
+				payloads = append(payloads, data)
+			}
+		}
+
+		for _, data := range payloads {
+			if err := stream.Send(&ems.CreateSubsReply{ResReqId: args.ReqId, Data: data}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Check the username and password metadata sent by the collector
+func (r *Router) authenticate(stream grpc.ServerStream) error {
+	return grpcauth.Check(stream.Context(), r.Username, r.Password)
+}
@@ -0,0 +1,243 @@
+package emulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	dialout "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt_dialout"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// Device emulates a router streaming telemetry to a collector over gRPC dial-out
+type Device struct {
+	// Node ID and subscription sent in every message
+	Name         string
+	Subscription string
+
+	// Collector address as host:port
+	Address string
+
+	// Encoding paths to stream, all supported paths if empty
+	Paths    []string
+	Encoding Encoding
+
+	// Rows per message
+	Rows int
+
+	// Interval between samples, every sample sends one message per path, one second if zero
+	Interval time.Duration
+
+	// Number of samples to send, zero streams until the context is done
+	Count int
+
+	// Fraction of messages sent malformed, a compact GPB row with invalid
+	// content or a truncated GPB-KV message
+	Malformed float64
+
+	// Fraction of messages replaced by MdtDialoutArgs.Errors with ErrorText,
+	// the device reopens its stream afterwards like a router does
+	ErrorRate float64
+	ErrorText string
+
+	// Options for the connection to the collector, plain text if empty
+	DialOptions []grpc.DialOption
+
+	sent      int64
+	malformed int64
+	errors    int64
+	redials   int64
+}
+
+// DeviceStats counts what a device sent
+type DeviceStats struct {
+	Sent      int64
+	Malformed int64
+	Errors    int64
+	Redials   int64
+}
+
+// Stats returns the counters of the device, it is safe to call while the device runs
+func (d *Device) Stats() DeviceStats {
+	return DeviceStats{
+		Sent:      atomic.LoadInt64(&d.sent),
+		Malformed: atomic.LoadInt64(&d.malformed),
+		Errors:    atomic.LoadInt64(&d.errors),
+		Redials:   atomic.LoadInt64(&d.redials),
+	}
+}
+
+// Devices returns count copies of template named after prefix and their index
+func Devices(count int, prefix string, template Device) []*Device {
+	devices := make([]*Device, count)
+	for i := range devices {
+		device := template
+		device.Name = fmt.Sprintf("%s%d", prefix, i+1)
+		devices[i] = &device
+	}
+	return devices
+}
+
+// RunDevices runs all devices until they are done or ctx is done and returns the first error
+func RunDevices(ctx context.Context, devices []*Device) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(devices))
+	for i, device := range devices {
+		wg.Add(1)
+		go func(i int, device *Device) {
+			defer wg.Done()
+			errs[i] = device.Run(ctx)
+		}(i, device)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run streams telemetry until Count samples are sent or ctx is done. A failed
+// stream is reopened after Interval, so the device survives collector restarts.
+func (d *Device) Run(ctx context.Context) error {
+	paths := d.Paths
+	if len(paths) == 0 {
+		paths = Paths()
+	}
+	generators := make([]*Generator, len(paths))
+	for i, path := range paths {
+		if _, ok := rowFuncs[path]; !ok {
+			return fmt.Errorf("no generator for encoding path %q", path)
+		}
+		generators[i] = &Generator{
+			Node:         d.Name,
+			Subscription: d.Subscription,
+			Path:         path,
+			Encoding:     d.Encoding,
+			Rows:         d.Rows,
+		}
+	}
+
+	opts := d.DialOptions
+	if len(opts) == 0 {
+		opts = []grpc.DialOption{grpc.WithInsecure()}
+	}
+	conn, err := grpc.DialContext(ctx, d.Address, opts...)
+	if err != nil {
+		return fmt.Errorf("%s: failed to dial %s: %v", d.Name, d.Address, err)
+	}
+	defer conn.Close()
+	client := dialout.NewGRPCMdtDialoutClient(conn)
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	var stream dialout.GRPCMdtDialout_MdtDialoutClient
+	var reqID int64
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sample := 0; d.Count == 0 || sample < d.Count; sample++ {
+		if sample > 0 {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+
+		for _, generator := range generators {
+			if stream == nil {
+				if stream, err = client.MdtDialout(ctx); err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					log.Printf("%s: failed to open dial-out stream: %v", d.Name, err)
+					stream = nil
+					break
+				}
+			}
+
+			reqID++
+			args := &dialout.MdtDialoutArgs{ReqId: reqID}
+			if d.ErrorRate > 0 && random.Float64() < d.ErrorRate {
+				args.Errors = d.ErrorText
+				atomic.AddInt64(&d.errors, 1)
+			} else {
+				message, err := generator.Next(time.Now())
+				if err != nil {
+					return err
+				}
+				malformed := d.Malformed > 0 && random.Float64() < d.Malformed
+				if args.Data, err = encode(message, malformed); err != nil {
+					return err
+				}
+				if malformed {
+					atomic.AddInt64(&d.malformed, 1)
+				}
+			}
+
+			if err = stream.Send(args); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Printf("%s: dial-out stream failed: %v", d.Name, err)
+				stream = nil
+				atomic.AddInt64(&d.redials, 1)
+				break
+			}
+			atomic.AddInt64(&d.sent, 1)
+
+			// The collector ends the stream on errors, open a new one
+			if len(args.Errors) > 0 {
+				closeStream(stream)
+				stream = nil
+			}
+		}
+	}
+
+	if stream != nil {
+		closeStream(stream)
+	}
+	return nil
+}
+
+// Serialize a message, malformed messages fail to decode at the collector
+func encode(message *telemetry.Telemetry, malformed bool) ([]byte, error) {
+	if malformed && len(message.GetDataGpb().GetRow()) > 0 {
+		// Length of the first field exceeds the content
+		message.DataGpb.Row[0].Content = []byte{0x0a, 0x05, 0x01}
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	if malformed && message.DataGpb == nil {
+		data = data[:len(data)-1]
+	}
+	return data, nil
+}
+
+// Close the sending side and wait for the collector to end the stream
+func closeStream(stream dialout.GRPCMdtDialout_MdtDialoutClient) {
+	stream.CloseSend()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err != io.EOF {
+				log.Printf("Dial-out stream ended: %v", err)
+			}
+			return
+		}
+	}
+}
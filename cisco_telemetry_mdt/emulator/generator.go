@@ -0,0 +1,259 @@
+/*
+Package emulator emulates Cisco devices streaming model driven telemetry, so
+the collectors can be exercised without a router.
+*/
+
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/mac_all"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/urib"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
+	lldp "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry/cisco_ios_xr_ethernet_lldp_oper/lldp/nodes/node/neighbors/summaries/summary"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Encoding of the generated telemetry messages
+type Encoding int
+
+// Encodings
+const (
+	// GPB is compact GPB, rows carry the generated messages
+	GPB Encoding = iota + 1
+
+	// GPBKV is self-describing GPB, the generated messages are converted into key/value trees
+	GPBKV
+)
+
+// ParseEncoding parses the encoding names "gpb" and "gpbkv"
+func ParseEncoding(name string) (Encoding, error) {
+	switch name {
+	case "gpb", "compact":
+		return GPB, nil
+	case "gpbkv", "kvgpb", "self-describing-gpb":
+		return GPBKV, nil
+	}
+	return 0, fmt.Errorf("unknown encoding %q", name)
+}
+
+// Builds the keys and content of row i of sample seq, keys are nil for paths without keys
+type rowFunc func(node string, seq uint64, i int) (keys proto.Message, content proto.Message)
+
+var rowFuncs = map[string]rowFunc{
+	mdt.PathLLDPSummary: lldpRow,
+	mdt.PathURIB:        uribRow,
+	mdt.PathMac:         macRow,
+}
+
+// Paths returns the encoding paths the generator supports
+func Paths() []string {
+	paths := make([]string, 0, len(rowFuncs))
+	for path := range rowFuncs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Generator builds synthetic telemetry messages for one encoding path of a device
+type Generator struct {
+	Node         string
+	Subscription string
+	Path         string
+	Encoding     Encoding
+
+	// Rows per message, at least one
+	Rows int
+
+	seq uint64
+}
+
+// Next returns the next sample, values change from one sample to the next
+func (g *Generator) Next(now time.Time) (*telemetry.Telemetry, error) {
+	row, ok := rowFuncs[g.Path]
+	if !ok {
+		return nil, fmt.Errorf("no generator for encoding path %q", g.Path)
+	}
+	g.seq++
+	rows := g.Rows
+	if rows < 1 {
+		rows = 1
+	}
+
+	ms := uint64(now.UnixNano() / int64(time.Millisecond))
+	message := &telemetry.Telemetry{
+		NodeId:              &telemetry.Telemetry_NodeIdStr{NodeIdStr: g.Node},
+		Subscription:        &telemetry.Telemetry_SubscriptionIdStr{SubscriptionIdStr: g.Subscription},
+		EncodingPath:        g.Path,
+		CollectionId:        g.seq,
+		CollectionStartTime: ms,
+		MsgTimestamp:        ms,
+		CollectionEndTime:   ms,
+	}
+
+	switch g.Encoding {
+	case GPB:
+		message.DataGpb = &telemetry.TelemetryGPBTable{}
+		for i := 0; i < rows; i++ {
+			keys, content := row(g.Node, g.seq, i)
+			gpb := &telemetry.TelemetryRowGPB{Timestamp: ms}
+			var err error
+			if keys != nil {
+				if gpb.Keys, err = proto.Marshal(keys); err != nil {
+					return nil, err
+				}
+			}
+			if gpb.Content, err = proto.Marshal(content); err != nil {
+				return nil, err
+			}
+			message.DataGpb.Row = append(message.DataGpb.Row, gpb)
+		}
+	case GPBKV:
+		for i := 0; i < rows; i++ {
+			keys, content := row(g.Node, g.seq, i)
+			entry := &telemetry.TelemetryField{
+				Timestamp: ms,
+				Fields: []*telemetry.TelemetryField{
+					{Name: "keys"},
+					{Name: "content", Fields: kvFields(proto.MessageReflect(content))},
+				},
+			}
+			if keys != nil {
+				entry.Fields[0].Fields = kvFields(proto.MessageReflect(keys))
+			}
+			message.DataGpbkv = append(message.DataGpbkv, entry)
+		}
+	default:
+		return nil, fmt.Errorf("unknown encoding %d", g.Encoding)
+	}
+	return message, nil
+}
+
+// Convert a message into GPB-KV fields like a device does, repeated fields
+// become siblings with the same name and enums are sent by name
+func kvFields(message protoreflect.Message) []*telemetry.TelemetryField {
+	var fields []*telemetry.TelemetryField
+	descriptors := message.Descriptor().Fields()
+	for i := 0; i < descriptors.Len(); i++ {
+		fd := descriptors.Get(i)
+		name := string(fd.Name())
+		switch {
+		case fd.IsList():
+			list := message.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				fields = append(fields, kvField(name, fd, list.Get(j)))
+			}
+		case fd.IsMap():
+			// None of the generated types use maps
+		case fd.Kind() == protoreflect.MessageKind:
+			if message.Has(fd) {
+				fields = append(fields, kvField(name, fd, message.Get(fd)))
+			}
+		default:
+			fields = append(fields, kvField(name, fd, message.Get(fd)))
+		}
+	}
+	return fields
+}
+
+// Convert a single value into a GPB-KV field
+func kvField(name string, fd protoreflect.FieldDescriptor, value protoreflect.Value) *telemetry.TelemetryField {
+	field := &telemetry.TelemetryField{Name: name}
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		field.Fields = kvFields(value.Message())
+	case protoreflect.EnumKind:
+		if enum := fd.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			field.ValueByType = &telemetry.TelemetryField_StringValue{StringValue: string(enum.Name())}
+		} else {
+			field.ValueByType = &telemetry.TelemetryField_Sint32Value{Sint32Value: int32(value.Enum())}
+		}
+	case protoreflect.StringKind:
+		field.ValueByType = &telemetry.TelemetryField_StringValue{StringValue: value.String()}
+	case protoreflect.BytesKind:
+		field.ValueByType = &telemetry.TelemetryField_BytesValue{BytesValue: value.Bytes()}
+	case protoreflect.BoolKind:
+		field.ValueByType = &telemetry.TelemetryField_BoolValue{BoolValue: value.Bool()}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		field.ValueByType = &telemetry.TelemetryField_Uint32Value{Uint32Value: uint32(value.Uint())}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		field.ValueByType = &telemetry.TelemetryField_Uint64Value{Uint64Value: value.Uint()}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		field.ValueByType = &telemetry.TelemetryField_Sint32Value{Sint32Value: int32(value.Int())}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		field.ValueByType = &telemetry.TelemetryField_Sint64Value{Sint64Value: value.Int()}
+	case protoreflect.FloatKind:
+		field.ValueByType = &telemetry.TelemetryField_FloatValue{FloatValue: float32(value.Float())}
+	case protoreflect.DoubleKind:
+		field.ValueByType = &telemetry.TelemetryField_DoubleValue{DoubleValue: value.Float()}
+	}
+	return field
+}
+
+// LLDP neighbor of interface i, the hold time counts down between samples
+func lldpRow(node string, seq uint64, i int) (proto.Message, proto.Message) {
+	ifname := fmt.Sprintf("GigabitEthernet0/0/0/%d", i)
+	device := fmt.Sprintf("%s-peer-%d", node, i)
+	keys := &lldp.LldpNeighbor_KEYS{
+		NodeName:      "0/RP0/CPU0",
+		InterfaceName: ifname,
+		DeviceId:      device,
+	}
+	content := &lldp.LldpNeighbor{
+		LldpNeighbor: []*lldp.LldpNeighborItem{{
+			ReceivingInterfaceName: ifname,
+			DeviceId:               device,
+			ChassisId:              fmt.Sprintf("00ca.fe00.%04x", i),
+			PortIdDetail:           fmt.Sprintf("Gi0/0/0/%d", i),
+			HoldTime:               uint32(120 - seq%90),
+			EnabledCapabilities:    "R",
+			Platform:               "Cisco IOS XRv 9000",
+		}},
+	}
+	return keys, content
+}
+
+// Route i with two next hops, the metric increases between samples
+func uribRow(node string, seq uint64, i int) (proto.Message, proto.Message) {
+	route := &urib.NxL3RouteProto{
+		VrfName:   "default",
+		Address:   fmt.Sprintf("10.%d.%d.0", i/256%256, i%256),
+		MaskLen:   24,
+		EventType: urib.UribEventType_URIB_EVENT_TYPE_UPDATE,
+	}
+	for hop := 1; hop <= 2; hop++ {
+		route.NextHop = append(route.NextHop, &urib.NxL3NextHopProto{
+			Address:      fmt.Sprintf("192.168.%d.%d", hop, i%254+1),
+			OutInterface: fmt.Sprintf("Ethernet1/%d", hop),
+			VrfName:      "default",
+			Owner:        "ospf-1",
+			Preference:   110,
+			Metric:       uint32(seq),
+		})
+	}
+	return nil, route
+}
+
+// MAC address entry i, the age increases between samples
+func macRow(node string, seq uint64, i int) (proto.Message, proto.Message) {
+	mac := fmt.Sprintf("00ca.fe%02x.%04x", i/65536%256, i%65536)
+	vlan := uint32(i%4094 + 1)
+	return nil, &mac_all.MacallList{
+		VlanId: vlan,
+		Mac:    mac,
+		Value: &mac_all.Mac{
+			Age:        uint32(seq),
+			Port:       fmt.Sprintf("Ethernet1/%d", i%48+1),
+			MacType:    mac_all.Type_MAC_ALL_ADDRESS_TYPE_DYNAMIC,
+			MacAddress: mac,
+			Vlan:       vlan,
+			EventType:  mac_all.MacAllEventType_MAC_ALL_EVENT_TYPE_UPDATE,
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package mdt
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CompactType creates the generated messages of the keys and content of a
+// compact GPB row, the keys are not decoded if Keys is nil
+type CompactType struct {
+	Keys    func() proto.Message
+	Content func() proto.Message
+}
+
+// Descriptors of the keys and content messages, keys is nil without a Keys type
+func (t CompactType) schema() (keys, content protoreflect.MessageDescriptor) {
+	if t.Keys != nil {
+		keys = proto.MessageReflect(t.Keys()).Descriptor()
+	}
+	return keys, proto.MessageReflect(t.Content()).Descriptor()
+}
+
+// Decode the keys of a row into tags and return its content as fields
+func (t CompactType) decode(keys []byte, content []byte, tags map[string]string) (map[string]interface{}, error) {
+	if t.Keys != nil && len(keys) > 0 {
+		message := t.Keys()
+		if err := proto.Unmarshal(keys, message); err != nil {
+			return nil, fmt.Errorf("invalid keys: %v", err)
+		}
+		keyFields := make(map[string]interface{})
+		flattenMessage("", proto.MessageReflect(message), keyFields)
+		for name, value := range keyFields {
+			tags[name] = fmt.Sprint(value)
+		}
+	}
+
+	message := t.Content()
+	if err := proto.Unmarshal(content, message); err != nil {
+		return nil, fmt.Errorf("invalid content: %v", err)
+	}
+	fields := make(map[string]interface{})
+	flattenMessage("", proto.MessageReflect(message), fields)
+	return fields, nil
+}
+
+// Flatten a decoded message into fields named after the field path. Scalars
+// are always written, so zero counters are not lost, nested messages only if set.
+func flattenMessage(prefix string, message protoreflect.Message, fields map[string]interface{}) {
+	descriptors := message.Descriptor().Fields()
+	for i := 0; i < descriptors.Len(); i++ {
+		fd := descriptors.Get(i)
+		name := string(fd.Name())
+		if len(prefix) > 0 {
+			name = prefix + "/" + name
+		}
+
+		switch {
+		case fd.IsList():
+			list := message.Get(fd).List()
+			for j := 0; j < list.Len(); j++ {
+				flattenValue(name+"/"+strconv.Itoa(j), fd, list.Get(j), fields)
+			}
+		case fd.IsMap():
+			message.Get(fd).Map().Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				flattenValue(name+"/"+key.String(), fd.MapValue(), value, fields)
+				return true
+			})
+		case fd.ContainingOneof() != nil || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+			if message.Has(fd) {
+				flattenValue(name, fd, message.Get(fd), fields)
+			}
+		default:
+			flattenValue(name, fd, message.Get(fd), fields)
+		}
+	}
+}
+
+// Flatten a single value of a field
+func flattenValue(name string, fd protoreflect.FieldDescriptor, value protoreflect.Value, fields map[string]interface{}) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		flattenMessage(name, value.Message(), fields)
+	case protoreflect.EnumKind:
+		if enum := fd.Enum().Values().ByNumber(value.Enum()); enum != nil {
+			fields[name] = string(enum.Name())
+		} else {
+			fields[name] = int32(value.Enum())
+		}
+	default:
+		fields[name] = value.Interface()
+	}
+}
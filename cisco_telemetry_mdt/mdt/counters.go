@@ -0,0 +1,130 @@
+package mdt
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// CounterKey identifies the telemetry of a device for an encoding path, both
+// are empty for messages that could not be decoded
+type CounterKey struct {
+	Device string
+	Path   string
+}
+
+// Count of the received messages, of the decoded and failed rows and of the
+// decoded rows truncated to the decoder limits
+type Count struct {
+	Messages  uint64
+	Rows      uint64
+	Errors    uint64
+	Truncated uint64
+}
+
+// Counters account messages and rows per device and encoding path. The zero
+// value is ready to use, a nil Counters counts nothing. It is safe for concurrent use.
+type Counters struct {
+	mu     sync.Mutex
+	counts map[CounterKey]*Count
+}
+
+// Return the count of a key, c.mu must be held
+func (c *Counters) count(device, path string) *Count {
+	if c.counts == nil {
+		c.counts = make(map[CounterKey]*Count)
+	}
+	key := CounterKey{Device: device, Path: path}
+	count, ok := c.counts[key]
+	if !ok {
+		count = &Count{}
+		c.counts[key] = count
+	}
+	return count
+}
+
+// Message counts a received message, a message that fails to decode also counts as error
+func (c *Counters) Message(device, path string, err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := c.count(device, path)
+	count.Messages++
+	if err != nil {
+		count.Errors++
+	}
+}
+
+// Row counts a decoded row, or a failed one if err is set
+func (c *Counters) Row(device, path string, err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := c.count(device, path)
+	if err != nil {
+		count.Errors++
+	} else {
+		count.Rows++
+	}
+}
+
+// Truncated counts decoded rows that were truncated to the decoder limits
+func (c *Counters) Truncated(device, path string, rows int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count(device, path).Truncated += uint64(rows)
+}
+
+// Counts returns a copy of all counts
+func (c *Counters) Counts() map[CounterKey]Count {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[CounterKey]Count, len(c.counts))
+	for key, count := range c.counts {
+		counts[key] = *count
+	}
+	return counts
+}
+
+// Print the counts sorted by device and encoding path
+func (c *Counters) Print(w io.Writer) error {
+	counts := c.Counts()
+	keys := make([]CounterKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Device != keys[j].Device {
+			return keys[i].Device < keys[j].Device
+		}
+		return keys[i].Path < keys[j].Path
+	})
+
+	for _, key := range keys {
+		device, path := key.Device, key.Path
+		if len(device) == 0 {
+			device = "-"
+		}
+		if len(path) == 0 {
+			path = "-"
+		}
+		count := counts[key]
+		_, err := fmt.Fprintf(w, "%s %s: %d messages, %d rows, %d errors, %d truncated\n",
+			device, path, count.Messages, count.Rows, count.Errors, count.Truncated)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
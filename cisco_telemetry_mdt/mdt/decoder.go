@@ -0,0 +1,354 @@
+/*
+Package mdt decodes Cisco model driven telemetry messages into metrics. It is
+shared by the dial-out and dial-in collectors and the replay tool.
+*/
+
+package mdt
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Decoder for telemetry messages in GPB-KV and compact GPB encoding
+type Decoder struct {
+	// Generated types of the compact GPB rows by encoding path, the entry
+	// with an empty path is used for all other paths. Rows without a type are skipped.
+	// GPB-KV fields repeated in the type are indexed as lists like the compact rows.
+	Compact map[string]CompactType
+
+	// Accounting of the messages and rows per device and encoding path, nothing is counted if nil
+	Counters *Counters
+
+	// Destination of the messages and rows that fail to decode, they are dropped if nil
+	DeadLetter *output.DeadLetter
+
+	// Limits of the GPB-KV flattening, zero is unlimited. Fields nested deeper
+	// than MaxDepth below keys or content are dropped, as are the fields and the
+	// tags of an entry beyond MaxFields each, the tags including the producer
+	// tags. Truncated entries are still decoded and counted.
+	MaxDepth  int
+	MaxFields int
+
+	// Messages larger than MaxMessageSize bytes are rejected by Decode, zero is unlimited
+	MaxMessageSize int
+}
+
+// Decode a serialized telemetry message into metrics, one per GPB-KV entry
+// or compact GPB row, named after the encoding path
+func (d *Decoder) Decode(data []byte) ([]*output.Metric, error) {
+	if d.MaxMessageSize > 0 && len(data) > d.MaxMessageSize {
+		err := fmt.Errorf("message of %d bytes exceeds the limit of %d bytes", len(data), d.MaxMessageSize)
+		d.Counters.Message("", "", err)
+		d.reject(&telemetry.Telemetry{}, -1, data, err)
+		return nil, err
+	}
+
+	message := &telemetry.Telemetry{}
+	if err := proto.Unmarshal(data, message); err != nil {
+		err = fmt.Errorf("invalid telemetry message: %v", err)
+		d.Counters.Message("", "", err)
+		d.reject(&telemetry.Telemetry{}, -1, data, err)
+		return nil, err
+	}
+	return d.DecodeMessage(message)
+}
+
+// DecodeMessage decodes a telemetry message into metrics. Rows that fail to
+// decode are skipped, the metrics of the other rows are returned with an
+// error describing the failed rows.
+func (d *Decoder) DecodeMessage(message *telemetry.Telemetry) ([]*output.Metric, error) {
+	var metrics []*output.Metric
+	failed := &rowErrors{path: message.EncodingPath}
+	device := message.GetNodeIdStr()
+	d.Counters.Message(device, message.EncodingPath, nil)
+
+	// The compact type of the path tells which GPB-KV fields are lists
+	compact, hasCompact := d.compactType(message.EncodingPath)
+	var keySchema, contentSchema protoreflect.MessageDescriptor
+	if hasCompact && len(message.DataGpbkv) > 0 {
+		keySchema, contentSchema = compact.schema()
+	}
+
+	flattener := &flattener{maxDepth: d.MaxDepth, maxFields: d.MaxFields}
+	truncated := 0
+	for i, gpbkv := range message.DataGpbkv {
+		flattener.truncated = false
+		metric := &output.Metric{
+			Name:      message.EncodingPath,
+			Timestamp: timestamp(gpbkv.Timestamp, message.MsgTimestamp),
+		}
+
+		// Populate tags and fields from toplevel GPBKV fields "keys" and "content"
+		for _, field := range gpbkv.Fields {
+			switch field.Name {
+			case "keys":
+				metric.Tags = baseTags(message, len(field.Fields))
+				metric.Tags["TimeStamp"] = metric.Timestamp.String()
+				flattener.flattenAll(field.Fields, keySchema, 1, metric.Tags, nil)
+			case "content":
+				metric.Fields = make(map[string]interface{}, len(field.Fields))
+				flattener.flattenAll(field.Fields, contentSchema, 1, metric.Tags, metric.Fields)
+			default:
+				log.Printf("I! Unexpected top-level MDT field: %s", field.Name)
+			}
+		}
+
+		if len(metric.Fields) > 0 && len(metric.Tags) > 0 && len(message.EncodingPath) > 0 {
+			metrics = append(metrics, metric)
+			d.Counters.Row(device, message.EncodingPath, nil)
+			if flattener.truncated {
+				truncated++
+			}
+		} else {
+			d.rejectRow(message, i, gpbkv, failed, fmt.Errorf("encoding path, keys or content empty"))
+		}
+	}
+	if truncated > 0 {
+		log.Printf("W! Truncated %d GPB-KV entries of %s from %s to %d levels and %d fields",
+			truncated, message.EncodingPath, device, d.MaxDepth, d.MaxFields)
+		d.Counters.Truncated(device, message.EncodingPath, truncated)
+	}
+
+	rows := message.GetDataGpb().GetRow()
+	if len(rows) == 0 {
+		return metrics, failed.err()
+	}
+	if !hasCompact {
+		log.Printf("I! No compact GPB decoder for %s, skipping message", message.EncodingPath)
+		return metrics, failed.err()
+	}
+
+	for i, row := range rows {
+		tags := baseTags(message, 0)
+		fields, err := compact.decode(row.Keys, row.Content, tags)
+		if err != nil {
+			d.rejectRow(message, i, row, failed, err)
+			continue
+		}
+		metrics = append(metrics, &output.Metric{
+			Name:      message.EncodingPath,
+			Tags:      tags,
+			Fields:    fields,
+			Timestamp: timestamp(row.Timestamp, message.MsgTimestamp),
+		})
+		d.Counters.Row(device, message.EncodingPath, nil)
+	}
+	return metrics, failed.err()
+}
+
+// Compact type of an encoding path, or the type for all other paths
+func (d *Decoder) compactType(path string) (CompactType, bool) {
+	compact, ok := d.Compact[path]
+	if !ok {
+		compact, ok = d.Compact[""]
+	}
+	return compact, ok
+}
+
+// Account a row that failed to decode and store it serialized in the dead-letter output
+func (d *Decoder) rejectRow(message *telemetry.Telemetry, index int, row proto.Message, failed *rowErrors, err error) {
+	failed.add(err)
+	d.Counters.Row(message.GetNodeIdStr(), message.EncodingPath, err)
+	if d.DeadLetter == nil {
+		return
+	}
+	data, marshalErr := proto.Marshal(row)
+	if marshalErr != nil {
+		log.Printf("E! Failed to serialize rejected row: %v", marshalErr)
+		return
+	}
+	d.reject(message, index, data, err)
+}
+
+// Store a rejected payload in the dead-letter output
+func (d *Decoder) reject(message *telemetry.Telemetry, index int, data []byte, err error) {
+	if d.DeadLetter == nil {
+		return
+	}
+	rejected := &output.Rejected{
+		Device: message.GetNodeIdStr(),
+		Path:   message.EncodingPath,
+		Row:    index,
+		Error:  err.Error(),
+		Data:   data,
+	}
+	if err := d.DeadLetter.Write(rejected); err != nil {
+		log.Printf("E! Failed to write dead letter: %v", err)
+	}
+}
+
+// Errors of the rows of a message, reported as one error
+type rowErrors struct {
+	path  string
+	count int
+	first error
+}
+
+func (e *rowErrors) add(err error) {
+	if e.count == 0 {
+		e.first = err
+	}
+	e.count++
+}
+
+// Error for the failed rows, nil if no row failed
+func (e *rowErrors) err() error {
+	switch e.count {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("invalid row of %s: %v", e.path, e.first)
+	}
+	return fmt.Errorf("%d invalid rows of %s, first: %v", e.count, e.path, e.first)
+}
+
+// Tags identifying the producer of a message
+func baseTags(message *telemetry.Telemetry, size int) map[string]string {
+	tags := make(map[string]string, size+4)
+	tags["Producer"] = message.GetNodeIdStr()
+	tags["Target"] = message.GetSubscriptionIdStr()
+	tags["EncodingPath"] = message.EncodingPath
+	return tags
+}
+
+// Top-level field may have measurement timestamp, if not use message timestamp, both in milliseconds
+func timestamp(measured uint64, message uint64) time.Time {
+	if measured == 0 {
+		measured = message
+	}
+	return time.Unix(int64(measured/1000), int64(measured%1000)*1000000)
+}
+
+// Flattens GPB-KV fields into tags and fields within the depth and field limits,
+// zero is unlimited
+type flattener struct {
+	namebuf   bytes.Buffer
+	maxDepth  int
+	maxFields int
+
+	// Set when fields were dropped to stay within the limits
+	truncated bool
+}
+
+// Flatten sibling fields, list entries are indexed in their order like the
+// compact lists, e.g. next_hop/0/address. A field is a list if it is repeated
+// in the schema, the message of the compact type at this level, even with a
+// single entry. Without a schema field, siblings sharing a name are a list and
+// a single entry is not indexed, as it cannot be told apart from a container.
+func (f *flattener) flattenAll(siblings []*telemetry.TelemetryField, schema protoreflect.MessageDescriptor, depth int, tags map[string]string, fields map[string]interface{}) {
+	var counts, indexes map[string]int
+	if len(siblings) > 1 {
+		counts = make(map[string]int, len(siblings))
+		for _, sibling := range siblings {
+			counts[sibling.Name]++
+		}
+	}
+
+	for _, sibling := range siblings {
+		fd := schemaField(schema, sibling.Name)
+		list := counts[sibling.Name] > 1
+		var child protoreflect.MessageDescriptor
+		if fd != nil {
+			list = fd.IsList()
+			child = fd.Message()
+		}
+
+		index := -1
+		if list {
+			if indexes == nil {
+				indexes = make(map[string]int)
+			}
+			index = indexes[sibling.Name]
+			indexes[sibling.Name]++
+		}
+		f.flatten(sibling, child, index, depth, tags, fields)
+	}
+}
+
+// Field of the schema named like a GPB-KV field, nil if there is none. Devices
+// may send the YANG names with dashes instead of the underscores of the protos.
+func schemaField(schema protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if schema == nil {
+		return nil
+	}
+	if fd := schema.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return schema.Fields().ByName(protoreflect.Name(strings.ReplaceAll(name, "-", "_")))
+}
+
+// Recursively parse GPBKV field structure into fields or tags, depth is 1 for
+// the fields directly below keys or content. The schema of the subfields is
+// nil if unknown. The index of a repeated field is appended to its name,
+// unless it is negative.
+func (f *flattener) flatten(field *telemetry.TelemetryField, schema protoreflect.MessageDescriptor, index int, depth int, tags map[string]string, fields map[string]interface{}) {
+	if f.maxDepth > 0 && depth > f.maxDepth {
+		f.truncated = true
+		return
+	}
+
+	namelen := f.namebuf.Len()
+	if namelen > 0 {
+		f.namebuf.WriteRune('/')
+	}
+	f.namebuf.WriteString(field.Name)
+	if index >= 0 {
+		f.namebuf.WriteRune('/')
+		f.namebuf.WriteString(strconv.Itoa(index))
+	}
+
+	// Decode Telemetry field value if set
+	var value interface{}
+	switch val := field.ValueByType.(type) {
+	case *telemetry.TelemetryField_BytesValue:
+		value = val.BytesValue
+	case *telemetry.TelemetryField_StringValue:
+		value = val.StringValue
+	case *telemetry.TelemetryField_BoolValue:
+		value = val.BoolValue
+	case *telemetry.TelemetryField_Uint32Value:
+		value = val.Uint32Value
+	case *telemetry.TelemetryField_Uint64Value:
+		value = val.Uint64Value
+	case *telemetry.TelemetryField_Sint32Value:
+		value = val.Sint32Value
+	case *telemetry.TelemetryField_Sint64Value:
+		value = val.Sint64Value
+	case *telemetry.TelemetryField_DoubleValue:
+		value = val.DoubleValue
+	case *telemetry.TelemetryField_FloatValue:
+		value = val.FloatValue
+	}
+
+	if value != nil {
+		// Distinguish between tags (keys) and fields (data) to write to
+		if fields != nil {
+			name := f.namebuf.String()
+			if _, exists := fields[name]; !exists && f.maxFields > 0 && len(fields) >= f.maxFields {
+				f.truncated = true
+			} else {
+				fields[name] = value
+			}
+		} else if tags != nil {
+			name := f.namebuf.String()
+			if _, exists := tags[name]; !exists && f.maxFields > 0 && len(tags) >= f.maxFields {
+				f.truncated = true
+			} else {
+				tags[name] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	f.flattenAll(field.Fields, schema, depth+1, tags, fields)
+	f.namebuf.Truncate(namelen)
+}
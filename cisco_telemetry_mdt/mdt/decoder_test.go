@@ -0,0 +1,343 @@
+package mdt
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/urib"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/proto"
+)
+
+var update = flag.Bool("update", false, "Regenerate the golden files in testdata")
+
+// Flattened form of a metric in the golden files
+type goldenMetric struct {
+	Name      string                 `json:"name"`
+	Timestamp time.Time              `json:"timestamp"`
+	Tags      map[string]string      `json:"tags"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// Decode every testdata/<name>.pb payload and testdata/<name>.cap capture and
+// compare the metrics with testdata/<name>.json
+func TestDecodeGolden(t *testing.T) {
+	// The TimeStamp tag of GPB-KV measurements is formatted in the local time zone
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+
+	payloads, err := filepath.Glob(filepath.Join("testdata", "*.pb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	captures, err := filepath.Glob(filepath.Join("testdata", "*.cap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloads = append(payloads, captures...)
+	if len(payloads) == 0 {
+		t.Fatal("no payloads in testdata")
+	}
+
+	for _, payload := range payloads {
+		name := strings.TrimSuffix(filepath.Base(payload), filepath.Ext(payload))
+		t.Run(name, func(t *testing.T) {
+			metrics, err := decodeFixture(payload)
+			if err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			got, err := marshalGolden(metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := strings.TrimSuffix(payload, filepath.Ext(payload)) + ".json"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v, run with -update to create it", err)
+			}
+			if diff := diffLines(string(want), string(got)); len(diff) > 0 {
+				t.Errorf("decoded metrics differ from %s, run with -update if the change is expected:\n%s", golden, diff)
+			}
+		})
+	}
+}
+
+// Decode a serialized telemetry message, or all MDT payloads of a capture in order
+func decodeFixture(path string) ([]*output.Metric, error) {
+	decoder := &Decoder{Compact: KnownCompact()}
+	if filepath.Ext(path) != ".cap" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.Decode(data)
+	}
+
+	reader, err := capture.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	var metrics []*output.Metric
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return metrics, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if record.Transport != capture.MdtDialout && record.Transport != capture.MdtDialIn {
+			continue
+		}
+		decoded, err := decoder.Decode(record.Data)
+		if err != nil {
+			return nil, fmt.Errorf("record from %s at %v: %v", record.Peer, record.Time, err)
+		}
+		metrics = append(metrics, decoded...)
+	}
+}
+
+// Encode metrics as indented JSON, map keys are sorted by encoding/json
+func marshalGolden(metrics []*output.Metric) ([]byte, error) {
+	golden := make([]goldenMetric, len(metrics))
+	for i, metric := range metrics {
+		golden[i] = goldenMetric{
+			Name:      metric.Name,
+			Timestamp: metric.Timestamp.UTC(),
+			Tags:      metric.Tags,
+			Fields:    metric.Fields,
+		}
+	}
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Line by line difference of two texts, empty if they are equal
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	var diff strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&diff, "line %d:\n-%s\n+%s\n", i+1, w, g)
+		}
+	}
+	return diff.String()
+}
+
+// A row that fails to decode is skipped, counted and stored with the error
+func TestDecodeInvalidRow(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "nx_urib.pb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := &telemetry.Telemetry{}
+	if err := proto.Unmarshal(data, message); err != nil {
+		t.Fatal(err)
+	}
+	message.DataGpb.Row[0].Content = []byte{0x0a, 0x05, 0x01}
+
+	var letters bytes.Buffer
+	decoder := &Decoder{Compact: KnownCompact(), Counters: &Counters{}, DeadLetter: output.NewDeadLetter(&letters)}
+	metrics, err := decoder.DecodeMessage(message)
+	if err == nil {
+		t.Error("no error for invalid row")
+	}
+	if len(metrics) != 1 || metrics[0].Fields["address"] != "10.0.1.0" {
+		t.Errorf("got metrics %v, want the second row", metrics)
+	}
+
+	want := Count{Messages: 1, Rows: 1, Errors: 1}
+	if got := decoder.Counters.Counts()[CounterKey{Device: "nx9k-1", Path: PathURIB}]; got != want {
+		t.Errorf("got count %+v, want %+v", got, want)
+	}
+
+	var rejected output.Rejected
+	if err := json.Unmarshal(letters.Bytes(), &rejected); err != nil {
+		t.Fatal(err)
+	}
+	row := &telemetry.TelemetryRowGPB{}
+	if err := proto.Unmarshal(rejected.Data, row); err != nil {
+		t.Fatal(err)
+	}
+	if rejected.Device != "nx9k-1" || rejected.Path != PathURIB || rejected.Row != 0 ||
+		len(rejected.Error) == 0 || !proto.Equal(row, message.DataGpb.Row[0]) {
+		t.Errorf("unexpected dead letter %+v", rejected)
+	}
+
+	// A message that fails to decode is counted without device and path
+	if _, err := decoder.Decode([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Error("no error for invalid message")
+	}
+	if got := decoder.Counters.Counts()[CounterKey{}]; got.Messages != 1 || got.Errors != 1 {
+		t.Errorf("got count %+v for invalid message", got)
+	}
+}
+
+// Fields beyond the limits are dropped and counted, oversize messages rejected
+func TestDecodeLimits(t *testing.T) {
+	leaf := func(name string, value uint64) *telemetry.TelemetryField {
+		return &telemetry.TelemetryField{Name: name, ValueByType: &telemetry.TelemetryField_Uint64Value{Uint64Value: value}}
+	}
+	message := &telemetry.Telemetry{
+		NodeId:       &telemetry.Telemetry_NodeIdStr{NodeIdStr: "router"},
+		EncodingPath: "path",
+		DataGpbkv: []*telemetry.TelemetryField{{
+			Fields: []*telemetry.TelemetryField{
+				{Name: "keys", Fields: []*telemetry.TelemetryField{leaf("id", 1)}},
+				{Name: "content", Fields: []*telemetry.TelemetryField{
+					leaf("a", 1),
+					{Name: "b", Fields: []*telemetry.TelemetryField{
+						leaf("c", 2),
+						{Name: "d", Fields: []*telemetry.TelemetryField{leaf("e", 3)}},
+					}},
+				}},
+			},
+		}},
+	}
+
+	decoder := &Decoder{Counters: &Counters{}, MaxDepth: 2}
+	metrics, err := decoder.DecodeMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": uint64(1), "b/c": uint64(2)}
+	if len(metrics) != 1 || fmt.Sprint(metrics[0].Fields) != fmt.Sprint(want) {
+		t.Errorf("got metrics %v, want fields %v", metrics, want)
+	}
+	if got := decoder.Counters.Counts()[CounterKey{Device: "router", Path: "path"}]; got.Truncated != 1 {
+		t.Errorf("got count %+v, want one truncated row", got)
+	}
+
+	// Key subtrees are bounded too, the producer tags count towards the limit
+	keys := message.DataGpbkv[0].Fields[0]
+	for i := 0; i < 10; i++ {
+		keys.Fields = append(keys.Fields, leaf(fmt.Sprintf("key%d", i), uint64(i)))
+	}
+	decoder = &Decoder{Counters: &Counters{}, MaxFields: 6}
+	metrics, err = decoder.DecodeMessage(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 1 || len(metrics[0].Tags) != 6 || metrics[0].Tags["id"] != "1" {
+		t.Errorf("got metrics %v, want 6 tags", metrics)
+	}
+	if got := decoder.Counters.Counts()[CounterKey{Device: "router", Path: "path"}]; got.Truncated != 1 {
+		t.Errorf("got count %+v, want one truncated row", got)
+	}
+
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder = &Decoder{MaxMessageSize: len(data) - 1}
+	if _, err := decoder.Decode(data); err == nil {
+		t.Error("message over the size limit decoded")
+	}
+}
+
+// A list with a single entry is indexed like the compact lists if the compact
+// type of the path is known, siblings sharing a name are indexed otherwise
+func TestDecodeGPBKVLists(t *testing.T) {
+	read := func(name string) *telemetry.Telemetry {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		message := &telemetry.Telemetry{}
+		if err := proto.Unmarshal(data, message); err != nil {
+			t.Fatal(err)
+		}
+		return message
+	}
+
+	// Routes with a single next hop in both encodings
+	compact := read("nx_urib.pb")
+	for _, row := range compact.DataGpb.Row {
+		route := &urib.NxL3RouteProto{}
+		if err := proto.Unmarshal(row.Content, route); err != nil {
+			t.Fatal(err)
+		}
+		route.NextHop = route.NextHop[:1]
+		content, err := proto.Marshal(route)
+		if err != nil {
+			t.Fatal(err)
+		}
+		row.Content = content
+	}
+	gpbkv := read("nx_urib_gpbkv.pb")
+	for _, entry := range gpbkv.DataGpbkv {
+		for _, field := range entry.Fields {
+			if field.Name != "content" {
+				continue
+			}
+			var siblings []*telemetry.TelemetryField
+			hops := 0
+			for _, sibling := range field.Fields {
+				if sibling.Name == "next_hop" {
+					if hops++; hops > 1 {
+						continue
+					}
+				}
+				siblings = append(siblings, sibling)
+			}
+			field.Fields = siblings
+		}
+	}
+
+	decoder := &Decoder{Compact: KnownCompact()}
+	want, err := decoder.DecodeMessage(compact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decoder.DecodeMessage(gpbkv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d GPB-KV metrics, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Fields["next_hop/0/address"] == nil || fmt.Sprint(got[i].Fields) != fmt.Sprint(want[i].Fields) {
+			t.Errorf("got fields %v, want %v", got[i].Fields, want[i].Fields)
+		}
+	}
+
+	// Without a compact type only repeated names are lists
+	gpbkv.EncodingPath = "unknown"
+	metrics, err := decoder.DecodeMessage(gpbkv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) == 0 || metrics[0].Fields["next_hop/address"] == nil {
+		t.Errorf("got metrics %v, want unindexed next hop fields", metrics)
+	}
+}
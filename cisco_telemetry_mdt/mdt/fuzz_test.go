@@ -0,0 +1,125 @@
+package mdt
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Decoded testdata payloads used to seed the corpus
+func seedMessages(f *testing.F) []*telemetry.Telemetry {
+	payloads, err := filepath.Glob(filepath.Join("testdata", "*.pb"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	var messages []*telemetry.Telemetry
+	for _, payload := range payloads {
+		data, err := os.ReadFile(payload)
+		if err != nil {
+			f.Fatal(err)
+		}
+		message := &telemetry.Telemetry{}
+		if err := proto.Unmarshal(data, message); err != nil {
+			f.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+// Malformed messages must return an error, never panic
+func FuzzDecode(f *testing.F) {
+	for _, message := range seedMessages(f) {
+		data, err := proto.Marshal(message)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0x0a, 0x05, 0x01})
+
+	decoder := &Decoder{Compact: KnownCompact()}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		metrics, err := decoder.Decode(data)
+		if err != nil {
+			return
+		}
+		for _, metric := range metrics {
+			if metric == nil || metric.Tags == nil || metric.Fields == nil {
+				t.Fatalf("incomplete metric %v", metric)
+			}
+		}
+	})
+}
+
+// Any GPB-KV field tree must flatten without panic and within the limits,
+// with or without a schema
+func FuzzFlattenGPBKV(f *testing.F) {
+	for _, message := range seedMessages(f) {
+		for _, entry := range message.DataGpbkv {
+			data, err := proto.Marshal(entry)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(data)
+		}
+	}
+
+	_, uribSchema := KnownCompact()[PathURIB].schema()
+	f.Fuzz(func(t *testing.T, data []byte) {
+		field := &telemetry.TelemetryField{}
+		if err := proto.Unmarshal(data, field); err != nil {
+			return
+		}
+		for _, flattener := range []*flattener{{}, {maxDepth: 2, maxFields: 3}} {
+			for _, schema := range []protoreflect.MessageDescriptor{nil, uribSchema} {
+				tags := make(map[string]string)
+				fields := make(map[string]interface{})
+				flattener.flatten(field, schema, -1, 1, tags, nil)
+				flattener.flatten(field, schema, -1, 1, tags, fields)
+				if flattener.namebuf.Len() != 0 {
+					t.Fatalf("name buffer not restored: %q", flattener.namebuf.String())
+				}
+				if flattener.maxFields > 0 && (len(fields) > flattener.maxFields || len(tags) > flattener.maxFields) {
+					t.Fatalf("got %d fields and %d tags, limit is %d", len(fields), len(tags), flattener.maxFields)
+				}
+			}
+		}
+	})
+}
+
+// Fuzz the keys and content of a row decoded with every known compact type
+func FuzzCompact(f *testing.F) {
+	known := KnownCompact()
+	paths := make([]string, 0, len(known))
+	for path := range known {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, message := range seedMessages(f) {
+		// Only rows of a known path are decoded with their own type
+		if _, ok := known[message.EncodingPath]; !ok {
+			continue
+		}
+		index := sort.SearchStrings(paths, message.EncodingPath)
+		for _, row := range message.GetDataGpb().GetRow() {
+			f.Add(uint8(index), row.Keys, row.Content)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, index uint8, keys []byte, content []byte) {
+		compact := known[paths[int(index)%len(paths)]]
+		tags := make(map[string]string)
+		fields, err := compact.decode(keys, content, tags)
+		if err == nil && fields == nil {
+			t.Fatal("no fields and no error")
+		}
+	})
+}
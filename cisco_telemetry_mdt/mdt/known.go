@@ -0,0 +1,37 @@
+package mdt
+
+import (
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/adjacency"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/mac_all"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/dial_out_nx/nx_telemetry_proto/urib"
+	lldp "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/telemetry/cisco_ios_xr_ethernet_lldp_oper/lldp/nodes/node/neighbors/summaries/summary"
+	"github.com/golang/protobuf/proto"
+)
+
+// Encoding paths of the compact GPB types generated in this repository, the
+// NX-OS paths are the paths of the native data source
+const (
+	PathLLDPSummary = "Cisco-IOS-XR-ethernet-lldp-oper:lldp/nodes/node/neighbors/summaries/summary"
+	PathURIB        = "route"
+	PathMac         = "mac"
+	PathAdjacency   = "adjacency"
+)
+
+// KnownCompact returns the compact GPB types generated in this repository by encoding path
+func KnownCompact() map[string]CompactType {
+	return map[string]CompactType{
+		PathLLDPSummary: {
+			Keys:    func() proto.Message { return &lldp.LldpNeighbor_KEYS{} },
+			Content: func() proto.Message { return &lldp.LldpNeighbor{} },
+		},
+		PathURIB: {
+			Content: func() proto.Message { return &urib.NxL3RouteProto{} },
+		},
+		PathMac: {
+			Content: func() proto.Message { return &mac_all.MacallList{} },
+		},
+		PathAdjacency: {
+			Content: func() proto.Message { return &adjacency.NxAdjacencyProto{} },
+		},
+	}
+}
@@ -0,0 +1,10 @@
+2KCisco-IOS-XR-ethernet-lldp-oper:lldp/nodes/node/neighbors/summaries/summary@H.P.b
+.R4
+
+0/RP0/CPU0GigabitEthernet0/0/0/0xrv9k-1-peer-0Z_\
+GigabitEthernet0/0/0/0xrv9k-1-peer-0"00ca.fe00.0000*	Gi0/0/0/08wBRJCisco IOS XRv 9000
+.R4
+
+0/RP0/CPU0GigabitEthernet0/0/0/1xrv9k-1-peer-1Z_\
+GigabitEthernet0/0/0/1xrv9k-1-peer-1"00ca.fe00.0001*	Gi0/0/0/18wBRJCisco IOS XRv 9000h.
+xrv9k-1lldp-dial-in-subs
\ No newline at end of file
@@ -0,0 +1,10 @@
+2route@H.P.b
+.Zy
+default10.0.0.0(2/
+192.168.1.1Ethernet1/1default"ospf-1(n02/
+192.168.2.1Ethernet1/2default"ospf-1(n0
+.Zy
+default10.0.1.0(2/
+192.168.1.2Ethernet1/1default"ospf-1(n02/
+192.168.2.2Ethernet1/2default"ospf-1(n0h.
+nx9k-11
\ No newline at end of file
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Build the alias tables from the configured aliases
+func (c *CiscoTelemetryGNMI) initAliases() error {
+	c.aliasMu.Lock()
+	c.aliases = make(map[string]string, len(c.Aliases))
+	c.aliasPaths = make(map[string]*gnmi.Path, len(c.Aliases))
+	c.aliasMu.Unlock()
+
+	for name, path := range c.Aliases {
+		gnmiPath, err := c.parseOriginPath(path)
+		if err != nil {
+			return fmt.Errorf("invalid alias %s: %v", name, err)
+		}
+		c.addAlias("", name, gnmiPath)
+	}
+	return nil
+}
+
+// Register an alias, client defined aliases have an empty address
+func (c *CiscoTelemetryGNMI) addAlias(address string, name string, path *gnmi.Path) {
+	name = strings.TrimPrefix(name, "#")
+	key, _ := c.handlePath(path, nil, "")
+
+	c.aliasMu.Lock()
+	defer c.aliasMu.Unlock()
+	if c.aliases == nil {
+		c.aliases = make(map[string]string)
+		c.aliasPaths = make(map[string]*gnmi.Path)
+	}
+	c.aliases[key] = name
+	c.aliasPaths[address+"#"+name] = path
+}
+
+// Measurement name for an alias path
+func (c *CiscoTelemetryGNMI) aliasName(path string) (string, bool) {
+	c.aliasMu.RLock()
+	defer c.aliasMu.RUnlock()
+	name, ok := c.aliases[path]
+	return name, ok
+}
+
+// Replace a prefix consisting of a single alias element by the aliased path
+func (c *CiscoTelemetryGNMI) resolveAlias(address string, path *gnmi.Path) *gnmi.Path {
+	if path == nil || len(path.Origin) > 0 || len(path.Elem) != 1 || !strings.HasPrefix(path.Elem[0].Name, "#") {
+		return path
+	}
+
+	c.aliasMu.RLock()
+	defer c.aliasMu.RUnlock()
+	// Aliases announced by the target take precedence over the configured ones
+	if resolved, ok := c.aliasPaths[address+path.Elem[0].Name]; ok {
+		return resolved
+	}
+	if resolved, ok := c.aliasPaths[path.Elem[0].Name]; ok {
+		return resolved
+	}
+	return path
+}
+
+// Create the SubscribeRequest defining the configured aliases on the target
+func (c *CiscoTelemetryGNMI) newAliasRequest() *gnmi.SubscribeRequest {
+	if len(c.Aliases) == 0 {
+		return nil
+	}
+
+	c.aliasMu.RLock()
+	defer c.aliasMu.RUnlock()
+	aliases := make([]*gnmi.Alias, 0, len(c.Aliases))
+	for name := range c.Aliases {
+		alias := "#" + strings.TrimPrefix(name, "#")
+		aliases = append(aliases, &gnmi.Alias{Path: c.aliasPaths[alias], Alias: alias})
+	}
+
+	return &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Aliases{
+			Aliases: &gnmi.AliasList{Alias: aliases},
+		},
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Capabilities queries every address and prints the supported models, encodings and GNMI version
+func (c *CiscoTelemetryGNMI) Capabilities(ctx context.Context) error {
+	targets, err := c.targets()
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, target := range targets {
+		response, err := c.capabilities(target.withCredentials(ctx), target)
+		if err != nil {
+			fmt.Printf("\n###### Capabilities of %v ######\nError: %v\n", target.Address, err)
+			failed = append(failed, target.Address)
+			continue
+		}
+		printCapabilities(target.Address, response)
+
+		if err = c.validateCapabilities(response, target); err != nil {
+			fmt.Printf("\nSubscriptions not supported: %v\n", err)
+		} else {
+			fmt.Printf("\nSubscriptions supported\n")
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to get capabilities of %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Dial a device and request its capabilities
+func (c *CiscoTelemetryGNMI) capabilities(ctx context.Context, target *Target) (*gnmi.CapabilityResponse, error) {
+	tlscfg, err := target.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.dial(ctx, target.Address, tlscfg)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	response, err := gnmi.NewGNMIClient(client).Capabilities(ctx, &gnmi.CapabilityRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get capabilities: %v", err)
+	}
+	return response, nil
+}
+
+// Check that the subscription origins of a device and the encoding are supported by it
+func (c *CiscoTelemetryGNMI) validateCapabilities(capabilities *gnmi.CapabilityResponse, target *Target) error {
+	var unsupported []string
+
+	encoding := gnmi.Encoding(gnmi.Encoding_value[strings.ToUpper(c.Encoding)])
+	if !supportsEncoding(capabilities, encoding) {
+		unsupported = append(unsupported, fmt.Sprintf("encoding %s", encoding))
+	}
+
+	origins := []string{target.Origin}
+	for _, subscription := range target.Subscriptions {
+		origins = append(origins, subscription.Origin)
+	}
+	for _, origin := range origins {
+		if len(origin) > 0 && !supportsOrigin(capabilities, origin) {
+			unsupported = append(unsupported, fmt.Sprintf("origin %s", origin))
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%s", strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+func supportsEncoding(capabilities *gnmi.CapabilityResponse, encoding gnmi.Encoding) bool {
+	for _, supported := range capabilities.SupportedEncodings {
+		if supported == encoding {
+			return true
+		}
+	}
+	return false
+}
+
+// An origin is supported if it names a model, or is the prefix of a model family such as "openconfig"
+func supportsOrigin(capabilities *gnmi.CapabilityResponse, origin string) bool {
+	for _, model := range capabilities.SupportedModels {
+		if model.Name == origin || strings.HasPrefix(model.Name, origin+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// Print the capabilities of a device
+func printCapabilities(address string, capabilities *gnmi.CapabilityResponse) {
+	fmt.Printf("\n###### Capabilities of %v ######\n", address)
+	fmt.Printf("GNMI version: %v\n", capabilities.GNMIVersion)
+
+	fmt.Printf("\n**** Encodings *****\n")
+	for _, encoding := range capabilities.SupportedEncodings {
+		fmt.Printf("%v\n", encoding)
+	}
+
+	fmt.Printf("\n**** Models *****\n")
+	for _, model := range capabilities.SupportedModels {
+		fmt.Printf("%v, %v, %v\n", model.Name, model.Organization, model.Version)
+	}
+}
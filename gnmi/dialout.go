@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	dialout "github.com/CiscoSE/grpc_collector/gnmi/gnmi_dialout"
+	"github.com/CiscoSE/grpc_collector/internal/grpcauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// StartDialOut listens on DialOutAddress for targets pushing their subscription
+// responses and returns, use Stop to end collection
+func (c *CiscoTelemetryGNMI) StartDialOut() error {
+	fmt.Printf("\nStarting GNMI dial-out Server\n")
+	if len(c.DialOutAddress) == 0 {
+		return fmt.Errorf("dial-out address is empty")
+	} else if err := c.initAliases(); err != nil {
+		return err
+	} else if err := c.initNaming(nil); err != nil {
+		return err
+	}
+
+	var opts []grpc.ServerOption
+	if len(c.DialOutTLSCert) > 0 {
+		cert, err := tls.LoadX509KeyPair(c.DialOutTLSCert, c.DialOutTLSKey)
+		if err != nil {
+			return fmt.Errorf("failed to load dial-out certificate: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+	}
+
+	lis, err := net.Listen("tcp", c.DialOutAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", c.DialOutAddress, err)
+	}
+	log.Printf("Listening for GNMI dial-out connections on %s", lis.Addr())
+
+	c.setDefaults()
+
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(context.Background())
+	if err = c.startStatus(ctx); err != nil {
+		c.cancel()
+		lis.Close()
+		return err
+	}
+
+	server := grpc.NewServer(opts...)
+	dialout.RegisterGNMIDialOutServer(server, c)
+
+	c.done = make(chan struct{})
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		defer close(c.done)
+		if err := server.Serve(lis); err != nil && ctx.Err() == nil {
+			log.Printf("GNMI dial-out server failed: %v", err)
+		}
+	}()
+	go func() {
+		defer c.wg.Done()
+		<-ctx.Done()
+		server.Stop()
+	}()
+	return nil
+}
+
+// Publish RPC server method for GNMI dial-out, the target streams subscribe responses
+func (c *CiscoTelemetryGNMI) Publish(stream dialout.GNMIDialOut_PublishServer) error {
+	if err := c.authenticate(stream.Context()); err != nil {
+		return err
+	}
+
+	address := "unknown"
+	if p, ok := peer.FromContext(stream.Context()); ok {
+		address = p.Addr.String()
+	}
+	log.Printf("Accepted GNMI dial-out connection from %s", address)
+	defer log.Printf("Closed GNMI dial-out connection from %s", address)
+	c.setSynced(address, false)
+	c.setState(address, StateConnected, nil)
+	defer c.deleteState(address)
+
+	for {
+		reply, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF && stream.Context().Err() == nil {
+				log.Printf("GNMI dial-out receive error from %s: %v", address, err)
+			}
+			return nil
+		}
+		c.record(capture.GNMIDialOut, address, reply)
+
+		// Errors reported by the target do not end the stream it opened
+		if err = c.handleSubscribeResponse(address, reply); err != nil {
+			log.Printf("Unexpected error: %v", err)
+		}
+	}
+}
+
+// Check the username and password metadata of a dial-out target against the configured credentials
+func (c *CiscoTelemetryGNMI) authenticate(ctx context.Context) error {
+	return grpcauth.Check(ctx, c.Username, c.Password)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+	"github.com/golang/protobuf/proto"
+)
+
+// Paths that parse must format back into the same path, others return an error
+func FuzzParsePath(f *testing.F) {
+	for _, path := range []string{
+		"",
+		"/",
+		"/interfaces/interface[name=Ethernet1/1]/state/counters",
+		"openconfig-interfaces:/interfaces/interface[name=*]",
+		`/a[x=\]\\]/b\/c`,
+		"/a[x=1][y=2]/.../b",
+		"/a[x=1",
+		"origin:a",
+	} {
+		f.Add(path)
+	}
+
+	// The default origin and the target come from the configuration, only the path is parsed
+	f.Fuzz(func(t *testing.T, path string) {
+		parsed, err := parsePath("openconfig", path, "router")
+		if err != nil {
+			return
+		}
+		if parsed.Target != "router" || len(parsed.Origin) == 0 {
+			t.Fatalf("parsePath(%q) = %v", path, parsed)
+		}
+
+		// A parsed path formats back into a path that parses the same
+		formatted := gnmipath.Format(parsed)
+		again, err := parsePath("", formatted, "router")
+		if err != nil {
+			t.Fatalf("formatted path %q of %q does not parse: %v", formatted, path, err)
+		}
+		if !proto.Equal(parsed, again) {
+			t.Fatalf("path %q parsed as %v, formatted as %q parsed as %v", path, parsed, formatted, again)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Get the paths from every address and write the returned notifications to the outputs
+func (c *CiscoTelemetryGNMI) Get(ctx context.Context, paths []string, dataType string, encoding string) error {
+	request, err := c.newGetRequest(paths, dataType, encoding)
+	if err != nil {
+		return err
+	}
+
+	c.setDefaults()
+	defer c.flush()
+	targets, err := c.targets()
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, target := range targets {
+		if err := c.get(target.withCredentials(ctx), target, request); err != nil {
+			log.Printf("Get from %s failed: %v", target.Address, err)
+			failed = append(failed, target.Address)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("get failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Create a new GNMI GetRequest
+func (c *CiscoTelemetryGNMI) newGetRequest(paths []string, dataType string, encoding string) (*gnmi.GetRequest, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no path to get")
+	}
+
+	gnmiPaths := make([]*gnmi.Path, len(paths))
+	for i, path := range paths {
+		gnmiPath, err := c.parseOriginPath(path)
+		if err != nil {
+			return nil, err
+		}
+		gnmiPaths[i] = gnmiPath
+	}
+
+	typ, ok := gnmi.GetRequest_DataType_value[strings.ToUpper(dataType)]
+	if !ok {
+		return nil, fmt.Errorf("invalid data type %s", dataType)
+	}
+	enc, ok := gnmi.Encoding_value[strings.ToUpper(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("invalid encoding %s", encoding)
+	}
+
+	return &gnmi.GetRequest{
+		Path:     gnmiPaths,
+		Type:     gnmi.GetRequest_DataType(typ),
+		Encoding: gnmi.Encoding(enc),
+	}, nil
+}
+
+// Dial a device and get the requested paths
+func (c *CiscoTelemetryGNMI) get(ctx context.Context, target *Target, request *gnmi.GetRequest) error {
+	address := target.Address
+	tlscfg, err := target.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	// The prefix carries the origin, prefix and target name of the device
+	prefix, err := parsePath(target.Origin, target.Prefix, target.Target)
+	if err != nil {
+		return err
+	}
+	request = proto.Clone(request).(*gnmi.GetRequest)
+	request.Prefix = prefix
+
+	client, err := c.dial(ctx, address, tlscfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	response, err := gnmi.NewGNMIClient(client).Get(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to get: %v", err)
+	}
+
+	for _, notification := range response.Notification {
+		c.handleNotification(address, notification)
+	}
+	return nil
+}
+
+// Parse a path given as [origin:]/path, the configured origin is used if none is given
+func (c *CiscoTelemetryGNMI) parseOriginPath(path string) (*gnmi.Path, error) {
+	return parsePath(c.Origin, path, "")
+}
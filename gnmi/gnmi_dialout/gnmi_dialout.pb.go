@@ -0,0 +1,193 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: gnmi_dialout.proto
+
+// Package implements the gNMI dial-out service, targets push their
+// subscription responses to the collector
+
+package gnmi_dialout
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	gnmi "github.com/openconfig/gnmi/proto/gnmi"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// PublishResponse is sent by the collector, an empty response carries no information
+type PublishResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PublishResponse) Reset()         { *m = PublishResponse{} }
+func (m *PublishResponse) String() string { return proto.CompactTextString(m) }
+func (*PublishResponse) ProtoMessage()    {}
+func (*PublishResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5d475f5741d23021, []int{0}
+}
+
+func (m *PublishResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishResponse.Unmarshal(m, b)
+}
+func (m *PublishResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishResponse.Marshal(b, m, deterministic)
+}
+func (m *PublishResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishResponse.Merge(m, src)
+}
+func (m *PublishResponse) XXX_Size() int {
+	return xxx_messageInfo_PublishResponse.Size(m)
+}
+func (m *PublishResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*PublishResponse)(nil), "gnmi_dialout.PublishResponse")
+}
+
+func init() { proto.RegisterFile("gnmi_dialout.proto", fileDescriptor_5d475f5741d23021) }
+
+var fileDescriptor_5d475f5741d23021 = []byte{
+	// 160 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x4a, 0xcf, 0xcb, 0xcd,
+	0x8c, 0x4f, 0xc9, 0x4c, 0xcc, 0xc9, 0x2f, 0x2d, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2,
+	0x41, 0x16, 0x93, 0x32, 0x48, 0xcf, 0x2c, 0xc9, 0x28, 0x4d, 0xd2, 0x4b, 0xce, 0xcf, 0xd5, 0xcf,
+	0x2f, 0x48, 0xcd, 0x4b, 0xce, 0xcf, 0x4b, 0xcb, 0x4c, 0xd7, 0x07, 0xa9, 0xd1, 0x07, 0xab, 0x87,
+	0x30, 0x41, 0x04, 0x44, 0xbf, 0x92, 0x20, 0x17, 0x7f, 0x40, 0x69, 0x52, 0x4e, 0x66, 0x71, 0x46,
+	0x50, 0x6a, 0x71, 0x41, 0x7e, 0x5e, 0x71, 0xaa, 0x51, 0x18, 0x17, 0x77, 0xba, 0x9f, 0xaf, 0xa7,
+	0x4b, 0x66, 0x62, 0x8e, 0x7f, 0x69, 0x89, 0x90, 0x3b, 0x17, 0x3b, 0x54, 0x85, 0x90, 0xb8, 0x1e,
+	0x58, 0x67, 0x70, 0x69, 0x52, 0x71, 0x72, 0x51, 0x66, 0x52, 0x2a, 0x4c, 0x8b, 0x94, 0xac, 0x1e,
+	0x8a, 0xd3, 0xd0, 0x4c, 0x54, 0x62, 0xd0, 0x60, 0x34, 0x60, 0x74, 0xe2, 0x8b, 0x42, 0x71, 0x6c,
+	0x12, 0x1b, 0xd8, 0x05, 0xc6, 0x80, 0x01, 0x00, 0xda, 0x4e, 0xaf, 0x90, 0xd7, 0x00, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// GNMIDialOutClient is the client API for GNMIDialOut service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type GNMIDialOutClient interface {
+	Publish(ctx context.Context, opts ...grpc.CallOption) (GNMIDialOut_PublishClient, error)
+}
+
+type gNMIDialOutClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGNMIDialOutClient(cc grpc.ClientConnInterface) GNMIDialOutClient {
+	return &gNMIDialOutClient{cc}
+}
+
+func (c *gNMIDialOutClient) Publish(ctx context.Context, opts ...grpc.CallOption) (GNMIDialOut_PublishClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_GNMIDialOut_serviceDesc.Streams[0], "/gnmi_dialout.gNMIDialOut/Publish", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &gNMIDialOutPublishClient{stream}
+	return x, nil
+}
+
+type GNMIDialOut_PublishClient interface {
+	Send(*gnmi.SubscribeResponse) error
+	Recv() (*PublishResponse, error)
+	grpc.ClientStream
+}
+
+type gNMIDialOutPublishClient struct {
+	grpc.ClientStream
+}
+
+func (x *gNMIDialOutPublishClient) Send(m *gnmi.SubscribeResponse) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *gNMIDialOutPublishClient) Recv() (*PublishResponse, error) {
+	m := new(PublishResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GNMIDialOutServer is the server API for GNMIDialOut service.
+type GNMIDialOutServer interface {
+	Publish(GNMIDialOut_PublishServer) error
+}
+
+// UnimplementedGNMIDialOutServer can be embedded to have forward compatible implementations.
+type UnimplementedGNMIDialOutServer struct {
+}
+
+func (*UnimplementedGNMIDialOutServer) Publish(srv GNMIDialOut_PublishServer) error {
+	return status.Errorf(codes.Unimplemented, "method Publish not implemented")
+}
+
+func RegisterGNMIDialOutServer(s *grpc.Server, srv GNMIDialOutServer) {
+	s.RegisterService(&_GNMIDialOut_serviceDesc, srv)
+}
+
+func _GNMIDialOut_Publish_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GNMIDialOutServer).Publish(&gNMIDialOutPublishServer{stream})
+}
+
+type GNMIDialOut_PublishServer interface {
+	Send(*PublishResponse) error
+	Recv() (*gnmi.SubscribeResponse, error)
+	grpc.ServerStream
+}
+
+type gNMIDialOutPublishServer struct {
+	grpc.ServerStream
+}
+
+func (x *gNMIDialOutPublishServer) Send(m *PublishResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *gNMIDialOutPublishServer) Recv() (*gnmi.SubscribeResponse, error) {
+	m := new(gnmi.SubscribeResponse)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _GNMIDialOut_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gnmi_dialout.gNMIDialOut",
+	HandlerType: (*GNMIDialOutServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Publish",
+			Handler:       _GNMIDialOut_Publish_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gnmi_dialout.proto",
+}
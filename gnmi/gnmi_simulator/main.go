@@ -0,0 +1,187 @@
+/*
+Serve a simulated gNMI target to test the gNMI collector without a device
+*/
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/gnmi/simulator"
+	"github.com/ghodss/yaml"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+var (
+	listen    = flag.String("listen", ":57400", "Address to serve gNMI on")
+	config    = flag.String("config", "", "JSON or YAML file mapping paths to values, a set of interface counters if empty")
+	username  = flag.String("username", "", "Username required from clients, not checked if empty")
+	password  = flag.String("password", "", "Password required from clients")
+	tlsCert   = flag.String("tls-cert", "", "TLS certificate file, plain text if empty")
+	tlsKey    = flag.String("tls-key", "", "TLS key file")
+	sample    = flag.Duration("sample", time.Second, "Sample interval of TARGET_DEFINED subscriptions")
+	increment = flag.Duration("increment", time.Second, "Interval to increment all integer values, disabled if zero")
+
+	// Scripted failures
+	fail      = flag.Int("fail", 0, "Fail the first RPCs with -fail-code")
+	failCode  = flag.String("fail-code", "UNAUTHENTICATED", "gRPC status code of the failed RPCs, e.g. UNAUTHENTICATED or UNAVAILABLE")
+	dropEvery = flag.Duration("drop-every", 0, "Interval to drop all subscriptions as if the connection was lost, disabled if zero")
+	control   = flag.Bool("control", false, "Read commands from standard input: drop, or fail <count> <code> [message]")
+)
+
+// Counters of a few interfaces, served if no configuration is given
+func defaultValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	for i := 0; i < 4; i++ {
+		prefix := fmt.Sprintf("openconfig-interfaces:/interfaces/interface[name=GigabitEthernet0/0/0/%d]/state", i)
+		values[prefix+"/oper-status"] = "UP"
+		for _, counter := range []string{"in-octets", "in-unicast-pkts", "out-octets", "out-unicast-pkts"} {
+			values[prefix+"/counters/"+counter] = uint64(0)
+		}
+	}
+	return values
+}
+
+// Read the values of the tree from a JSON or YAML file
+func readValues(file string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return values, nil
+}
+
+// Parse a gRPC status code name such as UNAVAILABLE
+func parseCode(name string) (codes.Code, error) {
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name)))); err != nil {
+		return code, fmt.Errorf("invalid status code %s", name)
+	}
+	return code, nil
+}
+
+// Run a control command on the target
+func command(target *simulator.Target, line string) error {
+	args := strings.Fields(line)
+	switch {
+	case len(args) == 0:
+		return nil
+	case args[0] == "drop" && len(args) == 1:
+		target.Drop()
+		return nil
+	case args[0] == "fail" && len(args) >= 3:
+		count, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid count %s", args[1])
+		}
+		code, err := parseCode(args[2])
+		if err != nil {
+			return err
+		}
+		message := "simulated failure"
+		if len(args) > 3 {
+			message = strings.Join(args[3:], " ")
+		}
+		target.FailNext(count, code, message)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q, use drop or fail <count> <code> [message]", line)
+}
+
+// Read control commands until the end of the input
+func readCommands(target *simulator.Target) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if err := command(target, scanner.Text()); err != nil {
+			log.Printf("%v", err)
+		}
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	values := defaultValues()
+	if len(*config) > 0 {
+		var err error
+		if values, err = readValues(*config); err != nil {
+			log.Fatalf("Failed to read configuration: %v", err)
+		}
+	}
+
+	target := simulator.NewTarget()
+	target.Username = *username
+	target.Password = *password
+	target.SampleInterval = *sample
+	if err := target.Load(values); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if *fail > 0 {
+		code, err := parseCode(*failCode)
+		if err != nil {
+			log.Fatalf("Invalid -fail-code: %v", err)
+		}
+		target.FailNext(*fail, code, "simulated failure")
+	}
+
+	var opts []grpc.ServerOption
+	if len(*tlsCert) > 0 {
+		creds, err := credentials.NewServerTLSFromFile(*tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	address, err := target.Start(*listen, opts...)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	log.Printf("Serving %d paths on %s", len(values), address)
+
+	var ticks, drops <-chan time.Time
+	if *increment > 0 {
+		ticker := time.NewTicker(*increment)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	if *dropEvery > 0 {
+		ticker := time.NewTicker(*dropEvery)
+		defer ticker.Stop()
+		drops = ticker.C
+	}
+	if *control {
+		go readCommands(target)
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	for {
+		select {
+		case <-c:
+			fmt.Printf("\nStopping the simulator\n")
+			target.Stop()
+			return
+		case <-ticks:
+			target.Increment("/", 1)
+		case <-drops:
+			log.Printf("Dropping all subscriptions")
+			target.Drop()
+		}
+	}
+}
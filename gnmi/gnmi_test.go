@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/gnmi/simulator"
+	"github.com/CiscoSE/grpc_collector/internal/testcert"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	inOctets   = "openconfig-interfaces:/interfaces/interface[name=eth0]/state/counters/in-octets"
+	operStatus = "openconfig-interfaces:/interfaces/interface[name=eth0]/state/oper-status"
+)
+
+// Simulated target with interface counters and oper-status
+func startTarget(t *testing.T, opts ...grpc.ServerOption) (*simulator.Target, string) {
+	t.Helper()
+	target := simulator.NewTarget()
+	target.Username, target.Password = "admin", "secret"
+	target.SampleInterval = 20 * time.Millisecond
+	target.Update(inOctets, uint64(1))
+	target.Update(operStatus, "UP")
+	address, err := target.Start("127.0.0.1:0", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(target.Stop)
+	return target, address
+}
+
+// Collector for address writing to memory, plain text unless tls is set
+func newCollector(address string, memory *output.Memory, tls *TLSSettings) *CiscoTelemetryGNMI {
+	if tls == nil {
+		tls = &TLSSettings{Enable: false}
+	}
+	return &CiscoTelemetryGNMI{
+		Addresses: []string{address},
+		Encoding:  "proto",
+		Username:  "admin",
+		Password:  "secret",
+		Redial:    20 * time.Millisecond,
+		TLS:       tls,
+		Outputs:   []output.Output{memory},
+		Subscriptions: []Subscription{
+			{
+				Origin:           "openconfig-interfaces",
+				Path:             "/interfaces/interface/state/counters",
+				SubscriptionMode: "sample",
+				SampleInterval:   20 * time.Millisecond,
+			},
+			{
+				Origin:           "openconfig-interfaces",
+				Path:             "/interfaces/interface/state/oper-status",
+				SubscriptionMode: "on_change",
+			},
+		},
+	}
+}
+
+// Run the collector in the background, the returned function cancels it and
+// waits for Run to return
+func run(t *testing.T, c *CiscoTelemetryGNMI) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	stopped := false
+	stop := func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Run failed: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("Run did not return after cancel")
+		}
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+// Wait until a metric passes check
+func waitMetric(t *testing.T, memory *output.Memory, check func(*output.Metric) bool) *output.Metric {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		for _, metric := range memory.Metrics() {
+			if check(metric) {
+				return metric
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no matching metric in %d metrics", memory.Len())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Wait until the state of the only target passes check
+func waitState(t *testing.T, c *CiscoTelemetryGNMI, check func(TargetState) bool) TargetState {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if states := c.Status(); len(states) == 1 && check(states[0]) {
+			return states[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected state %+v", c.Status())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Check for a field with the value, name is the last element of the field path
+func hasField(name string, value interface{}) func(*output.Metric) bool {
+	return func(metric *output.Metric) bool {
+		for field, v := range metric.Fields {
+			if strings.HasSuffix(field, "/"+name) && v == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestGNMISubscribe(t *testing.T) {
+	target, address := startTarget(t)
+	memory := &output.Memory{}
+	run(t, newCollector(address, memory, nil))
+
+	metric := waitMetric(t, memory, hasField("in_octets", uint64(1)))
+	if metric.Tags["name"] != "eth0" || metric.Tags["source"] == "" {
+		t.Errorf("unexpected tags %v", metric.Tags)
+	}
+	waitMetric(t, memory, hasField("oper_status", "UP"))
+
+	// Samples pick up new counter values, changes are sent on change
+	target.Increment(inOctets, 41)
+	waitMetric(t, memory, hasField("in_octets", uint64(42)))
+	target.Update(operStatus, "DOWN")
+	waitMetric(t, memory, hasField("oper_status", "DOWN"))
+}
+
+func TestGNMITLS(t *testing.T) {
+	files := testcert.Server(t)
+	_, address := startTarget(t, grpc.Creds(credentials.NewTLS(files.ServerConfig(t))))
+	memory := &output.Memory{}
+	c := newCollector(address, memory, &TLSSettings{Enable: true, CA: files.CA})
+	run(t, c)
+
+	waitMetric(t, memory, hasField("in_octets", uint64(1)))
+	waitState(t, c, func(state TargetState) bool { return state.State == StateConnected && state.Synced })
+}
+
+func TestGNMIReconnect(t *testing.T) {
+	target, address := startTarget(t)
+	memory := &output.Memory{}
+	c := newCollector(address, memory, nil)
+	run(t, c)
+	waitMetric(t, memory, hasField("in_octets", uint64(1)))
+
+	// A dropped stream and a failed redial are retried
+	target.FailNext(1, codes.Unavailable, "simulated outage")
+	target.Drop()
+	target.Update(inOctets, uint64(2))
+	waitMetric(t, memory, hasField("in_octets", uint64(2)))
+
+	// So is a restarted target on the same address
+	target.Stop()
+	if _, err := target.Start(address); err != nil {
+		t.Fatal(err)
+	}
+	target.Update(inOctets, uint64(3))
+	waitMetric(t, memory, hasField("in_octets", uint64(3)))
+
+	if target.Connections() < 3 {
+		t.Errorf("got %d connections, want at least 3", target.Connections())
+	}
+	waitState(t, c, func(state TargetState) bool { return state.State == StateConnected })
+}
+
+func TestGNMIMalformed(t *testing.T) {
+	target, address := startTarget(t)
+	memory := &output.Memory{}
+
+	// A path the collector cannot parse is rejected before subscribing
+	c := newCollector(address, memory, nil)
+	c.Subscriptions = append(c.Subscriptions, Subscription{Path: "/interfaces/interface[name=eth0"})
+	if err := c.Start(); err == nil {
+		c.Stop()
+		t.Fatal("invalid path accepted")
+	}
+
+	// A value that fails to decode does not end the subscription
+	c = newCollector(address, memory, nil)
+	run(t, c)
+	target.Update(operStatus, &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte("{not json")}})
+	target.Update(inOctets, uint64(2))
+	waitMetric(t, memory, hasField("in_octets", uint64(2)))
+	target.Update(operStatus, "UP")
+	waitMetric(t, memory, hasField("oper_status", "UP"))
+	waitState(t, c, func(state TargetState) bool { return state.State == StateConnected })
+}
+
+func TestGNMIAuth(t *testing.T) {
+	_, address := startTarget(t)
+	memory := &output.Memory{}
+	c := newCollector(address, memory, nil)
+	c.Password = "wrong"
+	run(t, c)
+
+	// Authentication errors are permanent and not redialed
+	state := waitState(t, c, func(state TargetState) bool { return state.State == StateFailed })
+	if state.ErrorClass != errorClass(ErrAuth) {
+		t.Errorf("got error class %q, want %q", state.ErrorClass, errorClass(ErrAuth))
+	}
+	if memory.Len() != 0 {
+		t.Errorf("got %d metrics without authentication", memory.Len())
+	}
+}
+
+func TestGNMIShutdown(t *testing.T) {
+	_, address := startTarget(t)
+	memory := &output.Memory{}
+	c := newCollector(address, memory, nil)
+	stop := run(t, c)
+	waitMetric(t, memory, hasField("in_octets", uint64(1)))
+
+	stop()
+	if states := c.Status(); len(states) != 1 || states[0].State != StateStopped {
+		t.Errorf("unexpected state after shutdown %+v", states)
+	}
+	count := memory.Len()
+	time.Sleep(50 * time.Millisecond)
+	if memory.Len() != count {
+		t.Errorf("metrics written after shutdown")
+	}
+}
+
+// A device that rejects the subscription on the first response is not connected and backs off
+func TestGNMIRejected(t *testing.T) {
+	target, address := startTarget(t)
+	target.FailNext(3, codes.Unavailable, "simulated overload")
+	memory := &output.Memory{}
+	c := newCollector(address, memory, nil)
+	run(t, c)
+
+	waitState(t, c, func(state TargetState) bool { return state.State != StateConnected && state.Retries >= 3 })
+	waitMetric(t, memory, hasField("in_octets", uint64(1)))
+	state := waitState(t, c, func(state TargetState) bool { return state.State == StateConnected })
+	if state.Retries != 0 || len(state.LastError) != 0 {
+		t.Errorf("error not cleared after connecting: %+v", state)
+	}
+}
+
+// The backoff grows up to the maximum without overflowing
+func TestBackoff(t *testing.T) {
+	redial := &backoff{base: 10 * time.Second, max: 100 * time.Second}
+	for i := 0; i < 100; i++ {
+		delay := redial.next()
+		if delay < 0 || delay > redial.max {
+			t.Fatalf("attempt %d: delay %v out of range", i, delay)
+		}
+		if i > 10 && delay < redial.max/2 {
+			t.Fatalf("attempt %d: delay %v below half the maximum", i, delay)
+		}
+	}
+	redial.reset()
+	if delay := redial.next(); delay > redial.base {
+		t.Errorf("delay %v after reset, want at most %v", delay, redial.base)
+	}
+}
+
+// A recorded subscription replays into the same measurements
+func TestGNMIReplay(t *testing.T) {
+	_, address := startTarget(t)
+	var recorded bytes.Buffer
+	writer, err := capture.NewWriter(&recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := &output.Memory{}
+	c := newCollector(address, memory, nil)
+	c.Recorder = writer
+	stop := run(t, c)
+	waitMetric(t, memory, hasField("in_octets", uint64(1)))
+	waitMetric(t, memory, hasField("oper_status", "UP"))
+	stop()
+
+	reader, err := capture.NewReader(&recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := &output.Memory{}
+	if err := newCollector(address, replayed, nil).Replay(reader); err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Len() != memory.Len() {
+		t.Fatalf("replayed %d metrics, collected %d", replayed.Len(), memory.Len())
+	}
+	for i, metric := range replayed.Metrics() {
+		if want := memory.Metrics()[i]; metric.Name != want.Name || fmt.Sprint(metric.Fields) != fmt.Sprint(want.Fields) {
+			t.Errorf("replayed %v, collected %v", metric, want)
+		}
+	}
+}
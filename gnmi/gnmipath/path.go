@@ -0,0 +1,276 @@
+/*
+Package gnmipath parses and formats GNMI paths following the gNMI path
+conventions, e.g. "openconfig:/interfaces/interface[name=Ethernet1/1]/state".
+
+A path string starts with an optional origin followed by a colon. Elements
+are separated by "/" and list keys are given as [name=value]. Within names
+"/", "[", "]" and "\" are escaped with "\", within key values "]" and "\"
+are escaped. The element names "*" and "..." and the key value "*" are
+wildcards, see Match.
+*/
+
+package gnmipath
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Wildcards
+const (
+	AnyElem  = "*"
+	AnyElems = "..."
+	AnyValue = "*"
+)
+
+// Error describes a syntax error in a path string
+type Error struct {
+	Path string
+	Pos  int
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid GNMI path %q at position %d: %s", e.Path, e.Pos, e.Msg)
+}
+
+// Parse a path string into a GNMI path. An empty string or "/" returns
+// a path without elements.
+func Parse(s string) (*gnmi.Path, error) {
+	path := &gnmi.Path{}
+	pos := 0
+
+	// Origin ends with the first colon found before any separator
+	if i := strings.IndexAny(s, ":/["); i >= 0 && s[i] == ':' {
+		path.Origin = s[:i]
+		pos = i + 1
+	}
+
+	if pos == len(s) {
+		return path, nil
+	}
+	if s[pos] != '/' {
+		return nil, &Error{Path: s, Pos: pos, Msg: "path must start with /"}
+	}
+	pos++
+
+	// Root path
+	if pos == len(s) {
+		return path, nil
+	}
+
+	for {
+		elem, next, err := parseElem(s, pos)
+		if err != nil {
+			return nil, err
+		}
+		path.Elem = append(path.Elem, elem)
+
+		if next == len(s) {
+			return path, nil
+		}
+		// parseElem only stops at the end or at a separator
+		pos = next + 1
+		if pos == len(s) {
+			return nil, &Error{Path: s, Pos: pos, Msg: "trailing /"}
+		}
+	}
+}
+
+// Parse an element starting at pos, return the element and the position after it
+func parseElem(s string, pos int) (*gnmi.PathElem, int, error) {
+	name, i, err := scan(s, pos, "/[]")
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(name) == 0 {
+		return nil, 0, &Error{Path: s, Pos: pos, Msg: "empty element name"}
+	}
+	if i < len(s) && s[i] == ']' {
+		return nil, 0, &Error{Path: s, Pos: i, Msg: "unexpected ]"}
+	}
+
+	elem := &gnmi.PathElem{Name: name}
+	for i < len(s) && s[i] == '[' {
+		start := i
+		key, j, err := scan(s, i+1, "=[]/")
+		if err != nil {
+			return nil, 0, err
+		}
+		if j == len(s) || s[j] != '=' {
+			return nil, 0, &Error{Path: s, Pos: j, Msg: "expected = in key"}
+		}
+		if len(key) == 0 {
+			return nil, 0, &Error{Path: s, Pos: i + 1, Msg: "empty key name"}
+		}
+
+		value, k, err := scan(s, j+1, "]")
+		if err != nil {
+			return nil, 0, err
+		}
+		if k == len(s) {
+			return nil, 0, &Error{Path: s, Pos: start, Msg: "unterminated key"}
+		}
+
+		if elem.Key == nil {
+			elem.Key = make(map[string]string)
+		}
+		if _, exists := elem.Key[key]; exists {
+			return nil, 0, &Error{Path: s, Pos: i + 1, Msg: fmt.Sprintf("duplicate key %s", key)}
+		}
+		elem.Key[key] = value
+
+		i = k + 1
+		if i < len(s) && s[i] != '[' && s[i] != '/' {
+			return nil, 0, &Error{Path: s, Pos: i, Msg: "expected [ or / after key"}
+		}
+	}
+	return elem, i, nil
+}
+
+// Scan unescaped characters from pos until one of stop or the end of s
+func scan(s string, pos int, stop string) (string, int, error) {
+	var builder strings.Builder
+	for i := pos; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			if i+1 == len(s) {
+				return "", 0, &Error{Path: s, Pos: i, Msg: "unterminated escape"}
+			}
+			i++
+			builder.WriteByte(s[i])
+		case strings.IndexByte(stop, c) >= 0:
+			return builder.String(), i, nil
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String(), len(s), nil
+}
+
+// Format a GNMI path as string, keys are sorted by name. Format is the
+// inverse of Parse, the deprecated Element field is ignored.
+func Format(path *gnmi.Path) string {
+	var builder strings.Builder
+	if len(path.GetOrigin()) > 0 {
+		builder.WriteString(path.GetOrigin())
+		builder.WriteByte(':')
+	}
+	if len(path.GetElem()) == 0 {
+		builder.WriteByte('/')
+		return builder.String()
+	}
+
+	for _, elem := range path.GetElem() {
+		builder.WriteByte('/')
+		escape(&builder, elem.Name, "/[]\\")
+
+		keys := make([]string, 0, len(elem.Key))
+		for key := range elem.Key {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			builder.WriteByte('[')
+			escape(&builder, key, "=[]/\\")
+			builder.WriteByte('=')
+			escape(&builder, elem.Key[key], "]\\")
+			builder.WriteByte(']')
+		}
+	}
+	return builder.String()
+}
+
+// Write s escaping the special characters
+func escape(builder *strings.Builder, s string, special string) {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(special, s[i]) >= 0 {
+			builder.WriteByte('\\')
+		}
+		builder.WriteByte(s[i])
+	}
+}
+
+// HasWildcards reports whether the path contains any wildcard
+func HasWildcards(path *gnmi.Path) bool {
+	for _, elem := range path.GetElem() {
+		if elem.Name == AnyElem || elem.Name == AnyElems {
+			return true
+		}
+		for _, value := range elem.Key {
+			if value == AnyValue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Match reports whether path matches pattern. In the pattern, the element
+// "*" matches any single element, "..." matches any number of elements
+// and the key value "*" matches any value. Keys missing in the pattern
+// match any value. An empty pattern origin matches any origin.
+func Match(pattern, path *gnmi.Path) bool {
+	if len(pattern.GetOrigin()) > 0 && pattern.GetOrigin() != path.GetOrigin() {
+		return false
+	}
+	return matchElems(pattern.GetElem(), path.GetElem(), false) >= 0
+}
+
+// MatchPrefix reports whether pattern matches path or one of its parents
+func MatchPrefix(pattern, path *gnmi.Path) bool {
+	if len(pattern.GetOrigin()) > 0 && pattern.GetOrigin() != path.GetOrigin() {
+		return false
+	}
+	return matchElems(pattern.GetElem(), path.GetElem(), true) >= 0
+}
+
+// TrimPrefix removes the shortest parent of path matched by pattern and
+// returns the remaining elements, it reports false if pattern does not match
+func TrimPrefix(pattern, path *gnmi.Path) (*gnmi.Path, bool) {
+	if len(pattern.GetOrigin()) > 0 && pattern.GetOrigin() != path.GetOrigin() {
+		return nil, false
+	}
+	n := matchElems(pattern.GetElem(), path.GetElem(), true)
+	if n < 0 {
+		return nil, false
+	}
+	return &gnmi.Path{Elem: path.GetElem()[n:]}, true
+}
+
+// Match elems against pattern and return the number of matched elements, -1 if they do not match
+func matchElems(pattern, elems []*gnmi.PathElem, prefix bool) int {
+	for i, want := range pattern {
+		if want.Name == AnyElems {
+			for j := i; j <= len(elems); j++ {
+				if n := matchElems(pattern[i+1:], elems[j:], prefix); n >= 0 {
+					return j + n
+				}
+			}
+			return -1
+		}
+		if i >= len(elems) || !matchElem(want, elems[i]) {
+			return -1
+		}
+	}
+	if !prefix && len(pattern) != len(elems) {
+		return -1
+	}
+	return len(pattern)
+}
+
+func matchElem(pattern, elem *gnmi.PathElem) bool {
+	if pattern.Name != AnyElem && pattern.Name != elem.Name {
+		return false
+	}
+	for key, want := range pattern.Key {
+		value, ok := elem.Key[key]
+		if want != AnyValue && (!ok || value != want) {
+			return false
+		}
+	}
+	return true
+}
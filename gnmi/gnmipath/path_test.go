@@ -0,0 +1,162 @@
+package gnmipath
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *gnmi.Path
+	}{
+		{"", &gnmi.Path{}},
+		{"/", &gnmi.Path{}},
+		{"openconfig:/", &gnmi.Path{Origin: "openconfig"}},
+		{"/a/b", &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a"}, {Name: "b"}}}},
+		{"openconfig-interfaces:/interfaces/interface[name=Ethernet1/1]/state", &gnmi.Path{
+			Origin: "openconfig-interfaces",
+			Elem: []*gnmi.PathElem{
+				{Name: "interfaces"},
+				{Name: "interface", Key: map[string]string{"name": "Ethernet1/1"}},
+				{Name: "state"},
+			},
+		}},
+		{"/a[x=1][y=2]", &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a", Key: map[string]string{"x": "1", "y": "2"}}}}},
+		{`/a[x=\]\\]/b\/c`, &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a", Key: map[string]string{"x": `]\`}}, {Name: "b/c"}}}},
+		{"/a[x=]", &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a", Key: map[string]string{"x": ""}}}}},
+		{"/a[x=b:c]", &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a", Key: map[string]string{"x": "b:c"}}}}},
+		{"/.../b[x=*]/*", &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "..."}, {Name: "b", Key: map[string]string{"x": "*"}}, {Name: "*"}}}},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !proto.Equal(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in  string
+		pos int
+	}{
+		{"a/b", 0},
+		{"origin:a", 7},
+		{"/a//b", 3},
+		{"/a/", 3},
+		{"/a[x=1", 2},
+		{"/a[x]", 4},
+		{"/a[=1]", 3},
+		{"/a[x=1]b", 7},
+		{"/a[x=1][x=2]", 8},
+		{"/a]", 2},
+		{`/a\`, 2},
+		{"/[x=1]", 1},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.in)
+		perr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Parse(%q) error = %v, want *Error", tt.in, err)
+			continue
+		}
+		if perr.Pos != tt.pos {
+			t.Errorf("Parse(%q) error position = %d, want %d (%v)", tt.in, perr.Pos, tt.pos, err)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		"/",
+		"openconfig:/",
+		"/a/b/c",
+		"openconfig-interfaces:/interfaces/interface[name=Ethernet1/1]/state/counters",
+		"/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=bgp]",
+		`/a[x=\]\\[]/b\/c\[d\]`,
+		"/.../state[x=*]/*",
+	}
+
+	for _, in := range tests {
+		path, err := Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", in, err)
+			continue
+		}
+		if got := Format(path); got != in {
+			t.Errorf("Format(Parse(%q)) = %q", in, got)
+		}
+		again, err := Parse(Format(path))
+		if err != nil || !proto.Equal(again, path) {
+			t.Errorf("Parse(Format(%v)) = %v, %v", path, again, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		match, prefix bool
+	}{
+		{"/a/b", "/a/b", true, true},
+		{"/a", "/a/b", false, true},
+		{"/a/b", "/a", false, false},
+		{"/a/*", "/a/b", true, true},
+		{"/a/*/c", "/a/b/c", true, true},
+		{"/.../c", "/a/b/c", true, true},
+		{"/a/...", "/a", true, true},
+		{"/a/.../d", "/a/b/c", false, false},
+		{"/a[x=1]", "/a[x=1][y=2]", true, true},
+		{"/a[x=*]", "/a[x=1]", true, true},
+		{"/a[x=2]", "/a[x=1]", false, false},
+		{"openconfig:/a", "/a", false, false},
+		{"/a", "openconfig:/a", true, true},
+	}
+
+	for _, tt := range tests {
+		pattern, _ := Parse(tt.pattern)
+		path, _ := Parse(tt.path)
+		if got := Match(pattern, path); got != tt.match {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.match)
+		}
+		if got := MatchPrefix(pattern, path); got != tt.prefix {
+			t.Errorf("MatchPrefix(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.prefix)
+		}
+	}
+}
+
+func TestTrimPrefix(t *testing.T) {
+	tests := []struct {
+		pattern, path, rest string
+		ok                  bool
+	}{
+		{"/a/b", "/a/b/c/d", "/c/d", true},
+		{"/a/b", "/a/b", "/", true},
+		{"/a/*", "/a/b/c", "/c", true},
+		{"/.../b", "/a/b/c/b/d", "/c/b/d", true},
+		{"/a[x=*]", "/a[x=1]/b", "/b", true},
+		{"/a/c", "/a/b/c", "", false},
+	}
+
+	for _, tt := range tests {
+		pattern, _ := Parse(tt.pattern)
+		path, _ := Parse(tt.path)
+		rest, ok := TrimPrefix(pattern, path)
+		if ok != tt.ok {
+			t.Errorf("TrimPrefix(%q, %q) reports %v, want %v", tt.pattern, tt.path, ok, tt.ok)
+			continue
+		}
+		if ok && Format(rest) != tt.rest {
+			t.Errorf("TrimPrefix(%q, %q) = %q, want %q", tt.pattern, tt.path, Format(rest), tt.rest)
+		}
+	}
+}
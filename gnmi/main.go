@@ -1,23 +1,33 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"os"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+	"github.com/CiscoSE/grpc_collector/internal/grpcerr"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // CiscoTelemetryGNMI plugin instance
@@ -25,6 +35,9 @@ type CiscoTelemetryGNMI struct {
 	Addresses     []string
 	Subscriptions []Subscription
 
+	// Devices with their own settings, collected in addition to Addresses
+	Targets []Target
+
 	// Optional subscription configuration
 	Encoding    string
 	Origin      string
@@ -32,20 +45,97 @@ type CiscoTelemetryGNMI struct {
 	Target      string
 	UpdatesOnly bool
 
+	// Path aliases as name to [origin:]/path, alias names are used as measurement names
+	Aliases map[string]string
+
+	// Subscription list mode: stream (default), once or poll
+	Mode string
+
+	// Interval between polls in poll mode, if zero polls are only sent on Poll()
+	PollInterval time.Duration
+
 	// Cisco IOS XR credentials
 	Username string
 	Password string
 
-	// Redial
+	// Initial redial interval, doubled after every failed attempt up to MaxRedial
 	Redial time.Duration
 
-	// GRPC TLS settings
-	EnableTLS bool
+	// Maximum redial interval, ten times Redial if zero
+	MaxRedial time.Duration
+
+	// Listen address of the HTTP status endpoint, disabled if empty
+	StatusAddress string
+
+	// Additional metadata sent with every request
+	Metadata map[string]string
+
+	// GRPC TLS settings, TLS without verification is used if nil
+	TLS *TLSSettings
+
+	// Check origins and encoding against the device capabilities before subscribing
+	ValidateCapabilities bool
+
+	// Listen address for GNMI dial-out, targets are authenticated with Username and Password
+	DialOutAddress string
+
+	// Dial-out server certificate and key files, plain text if empty
+	DialOutTLSCert string
+	DialOutTLSKey  string
+
+	// Join leaf-list elements with this separator, leaf-lists are expanded into indexed fields if empty
+	LeafListSeparator string
+
+	// Resolver for the types of Any values, types linked into the binary are resolved if nil
+	AnyResolver jsonpb.AnyResolver
+
+	// Destinations for the decoded measurements, prints to stdout if empty
+	Outputs []output.Output
+
+	// Capture of the raw subscribe responses, nothing is recorded if nil
+	Recorder *capture.Writer
 
 	// Internal state
 
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
+	done   chan struct{}
+
+	// Alias tables, path to measurement name and alias to path
+	aliasMu    sync.RWMutex
+	aliases    map[string]string
+	aliasPaths map[string]*gnmi.Path
+
+	// Subscriptions with measurement naming or tag mapping per address
+	named map[string][]namedSubscription
+
+	// Connection state per address
+	stateMu sync.Mutex
+	states  map[string]*TargetState
+
+	// Initial sync state per address
+	syncMu sync.Mutex
+	synced map[string]bool
+
+	// Poll triggers of the active poll subscriptions
+	pollMu      sync.Mutex
+	pollTrigger map[chan struct{}]struct{}
+}
+
+// DeviceError is an error reported by a GNMI device in a subscribe response
+type DeviceError struct {
+	Address string
+	Code    codes.Code
+	Message string
+}
+
+func (e *DeviceError) Error() string {
+	return fmt.Sprintf("GNMI device %s reported error %v: %s", e.Address, e.Code, e.Message)
+}
+
+// GRPCStatus returns the status reported by the device, to classify it like gRPC errors
+func (e *DeviceError) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
 }
 
 // Subscription for a GNMI client
@@ -58,66 +148,247 @@ type Subscription struct {
 	SampleInterval    time.Duration
 	HeartbeatInterval time.Duration
 	SuppressRedundant bool
+
+	// Measurement naming, updates of subscriptions without any of these settings
+	// are named after the notification prefix and tagged with all path keys
+
+	// Measurement name, derived from the subscription path if empty
+	Name string
+
+	// Path keys written as tags, as key name or element/key, all keys if empty
+	TagKeys []string
+
+	// Tag names of path keys, from key name or element/key
+	TagRenames map[string]string
+
+	// Add the origin as tag and to derived measurement names
+	IncludeOrigin bool
 }
 
-// Start the http listener service
+// Start a subscription for every address and return, use Stop to end collection
 func (c *CiscoTelemetryGNMI) Start() error {
 	fmt.Printf("\nStarting GNMI Server\n")
 	var err error
 	var ctx context.Context
-	var tlscfg *tls.Config
-	var request *gnmi.SubscribeRequest
-
-	ctx, c.cancel = context.WithCancel(context.Background())
+	var targets []*Target
 
 	// Validate configuration
-	if request, err = c.newSubscribeRequest(); err != nil {
+	if targets, err = c.targets(); err != nil {
 		return err
 	} else if c.Redial.Nanoseconds() <= 0 {
 		return fmt.Errorf("redial duration must be positive")
+	} else if err = c.initAliases(); err != nil {
+		return err
+	} else if err = c.initNaming(targets); err != nil {
+		return err
 	}
 
-	// TODO: Should not be hardcoded!
-	tlscfg = &tls.Config{
-		InsecureSkipVerify: true,
+	requests := make([]*gnmi.SubscribeRequest, len(targets))
+	tlscfgs := make([]*tls.Config, len(targets))
+	for i, target := range targets {
+		if requests[i], err = c.newSubscribeRequest(target); err != nil {
+			return fmt.Errorf("invalid subscription for %s: %v", target.Address, err)
+		}
+		if tlscfgs[i], err = target.tlsConfig(); err != nil {
+			return fmt.Errorf("invalid TLS settings for %s: %v", target.Address, err)
+		}
 	}
 
-	if len(c.Username) > 0 {
-		ctx = metadata.AppendToOutgoingContext(ctx, "username", c.Username, "password", c.Password)
+	c.setDefaults()
+
+	ctx, c.cancel = context.WithCancel(context.Background())
+	if err = c.startStatus(ctx); err != nil {
+		c.cancel()
+		return err
 	}
 
 	// Create a goroutine for each device, dial and subscribe
-	fmt.Printf("\nCreating subscriptions for %v \n", c.Addresses)
-	c.wg.Add(len(c.Addresses))
-	for _, addr := range c.Addresses {
-		fmt.Printf("\nStarting for collection for %v\n", addr)
-		go func(address string) {
+	var pending sync.WaitGroup
+	pending.Add(len(targets))
+	c.wg.Add(len(targets))
+	for i, target := range targets {
+		fmt.Printf("\nStarting for collection for %v\n", target.Address)
+		go func(target *Target, tlscfg *tls.Config, request *gnmi.SubscribeRequest) {
 			defer c.wg.Done()
-			for ctx.Err() == nil {
-				if err := c.subscribeGNMI(ctx, address, tlscfg, request); err != nil && ctx.Err() == nil {
-					log.Printf("Unexpected error: %v", err)
-				}
+			defer pending.Done()
+			c.collect(target.withCredentials(ctx), target, tlscfg, request)
+		}(target, tlscfgs[i], requests[i])
+	}
 
-				select {
-				case <-ctx.Done():
-				case <-time.After(c.Redial):
-				}
+	c.done = make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(c.done)
+	}()
+	return nil
+}
+
+// Subscribe to a device and redial with backoff until ctx is done or a permanent error occurs
+func (c *CiscoTelemetryGNMI) collect(ctx context.Context, target *Target, tlscfg *tls.Config, request *gnmi.SubscribeRequest) {
+	address := target.Address
+	once := request.GetSubscribe().Mode == gnmi.SubscriptionList_ONCE
+	redial := &backoff{base: c.Redial, max: c.MaxRedial}
+	if redial.max < redial.base {
+		redial.max = 10 * redial.base
+	}
+
+	for ctx.Err() == nil {
+		c.setState(address, StateConnecting, nil)
+		err := c.subscribeGNMI(ctx, target, tlscfg, request)
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			err = grpcerr.Classify(err)
+			log.Printf("Unexpected error: %v", err)
+		}
+
+		// A once subscription is not redialed
+		if once {
+			if err != nil {
+				c.setState(address, StateFailed, err)
+			} else {
+				c.setState(address, StateDone, nil)
 			}
-		}(addr)
+			return
+		}
+		if isPermanent(err) {
+			log.Printf("Not redialing GNMI device %s after permanent error", address)
+			c.setState(address, StateFailed, err)
+			return
+		}
+
+		// Start over with the initial interval if the device was connected
+		if c.connected(address) {
+			redial.reset()
+		}
+		delay := redial.next()
+		c.setState(address, StateBackoff, err)
+		log.Printf("Redialing GNMI device %s in %v", address, delay.Round(time.Millisecond))
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
 	}
+	c.setState(address, StateStopped, nil)
+}
+
+// Run the collector until ctx is done, the process is interrupted or all once subscriptions are complete
+func (c *CiscoTelemetryGNMI) Run(ctx context.Context) error {
+	return c.run(ctx, c.Start)
+}
+
+// RunDialOut accepts dial-out connections until ctx is done or the process is interrupted
+func (c *CiscoTelemetryGNMI) RunDialOut(ctx context.Context) error {
+	return c.run(ctx, c.StartDialOut)
+}
+
+// Start collection and wait for it to end
+func (c *CiscoTelemetryGNMI) run(ctx context.Context, start func() error) error {
+	if err := start(); err != nil {
+		return err
+	}
+	defer c.Stop()
+
 	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(ch)
+
 	select {
 	case <-ch:
 		fmt.Printf("\nManually cancelled the session\n")
-		return nil
+	case <-ctx.Done():
+	case <-c.done:
+	}
+	return nil
+}
+
+// Poll sends a poll request on every active poll subscription
+func (c *CiscoTelemetryGNMI) Poll() {
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
+	for trigger := range c.pollTrigger {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Register a poll trigger for an active poll subscription, the returned function unregisters it
+func (c *CiscoTelemetryGNMI) registerPoll() (chan struct{}, func()) {
+	trigger := make(chan struct{}, 1)
+
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
+	if c.pollTrigger == nil {
+		c.pollTrigger = make(map[chan struct{}]struct{})
+	}
+	c.pollTrigger[trigger] = struct{}{}
+
+	return trigger, func() {
+		c.pollMu.Lock()
+		defer c.pollMu.Unlock()
+		delete(c.pollTrigger, trigger)
+	}
+}
+
+// Send poll requests on the timer or on Poll() until ctx is done
+func (c *CiscoTelemetryGNMI) sendPolls(ctx context.Context, subscribeClient gnmi.GNMI_SubscribeClient) {
+	trigger, unregister := c.registerPoll()
+	defer unregister()
+
+	var tick <-chan time.Time
+	if c.PollInterval > 0 {
+		ticker := time.NewTicker(c.PollInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	poll := &gnmi.SubscribeRequest{Request: &gnmi.SubscribeRequest_Poll{Poll: &gnmi.Poll{}}}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-trigger:
+		}
+		if err := subscribeClient.Send(poll); err != nil {
+			log.Printf("failed to send poll request: %v", err)
+			return
+		}
 	}
 }
 
-// Create a new GNMI SubscribeRequest
-func (c *CiscoTelemetryGNMI) newSubscribeRequest() (*gnmi.SubscribeRequest, error) {
+// Set defaults for the optional settings
+func (c *CiscoTelemetryGNMI) setDefaults() {
+	if len(c.Outputs) == 0 {
+		c.Outputs = []output.Output{output.NewPrinter(os.Stdout)}
+	}
+}
+
+// Dial a GNMI device
+func (c *CiscoTelemetryGNMI) dial(ctx context.Context, address string, tlscfg *tls.Config) (*grpc.ClientConn, error) {
+	var opt grpc.DialOption
+	if tlscfg != nil {
+		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlscfg))
+	} else {
+		opt = grpc.WithInsecure()
+	}
+
+	client, err := grpc.DialContext(ctx, address, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial: %w", err)
+	}
+	return client, nil
+}
+
+// Create a new GNMI SubscribeRequest for a device
+func (c *CiscoTelemetryGNMI) newSubscribeRequest(target *Target) (*gnmi.SubscribeRequest, error) {
 	// Create subscription objects
-	subscriptions := make([]*gnmi.Subscription, len(c.Subscriptions))
-	for i, subscription := range c.Subscriptions {
+	subscriptions := make([]*gnmi.Subscription, len(target.Subscriptions))
+	for i, subscription := range target.Subscriptions {
 		gnmiPath, err := parsePath(subscription.Origin, subscription.Path, "")
 		if err != nil {
 			return nil, err
@@ -136,7 +407,7 @@ func (c *CiscoTelemetryGNMI) newSubscribeRequest() (*gnmi.SubscribeRequest, erro
 	}
 
 	// Construct subscribe request
-	gnmiPath, err := parsePath(c.Origin, c.Prefix, c.Target)
+	gnmiPath, err := parsePath(target.Origin, target.Prefix, target.Target)
 	if err != nil {
 		return nil, err
 	}
@@ -145,11 +416,20 @@ func (c *CiscoTelemetryGNMI) newSubscribeRequest() (*gnmi.SubscribeRequest, erro
 		return nil, fmt.Errorf("unsupported encoding %s", c.Encoding)
 	}
 
+	mode := gnmi.SubscriptionList_STREAM
+	if len(c.Mode) > 0 {
+		value, ok := gnmi.SubscriptionList_Mode_value[strings.ToUpper(c.Mode)]
+		if !ok {
+			return nil, fmt.Errorf("invalid subscription list mode %s", c.Mode)
+		}
+		mode = gnmi.SubscriptionList_Mode(value)
+	}
+
 	return &gnmi.SubscribeRequest{
 		Request: &gnmi.SubscribeRequest_Subscribe{
 			Subscribe: &gnmi.SubscriptionList{
 				Prefix:       gnmiPath,
-				Mode:         gnmi.SubscriptionList_STREAM,
+				Mode:         mode,
 				Encoding:     gnmi.Encoding(gnmi.Encoding_value[strings.ToUpper(c.Encoding)]),
 				Subscription: subscriptions,
 				UpdatesOnly:  c.UpdatesOnly,
@@ -159,92 +439,266 @@ func (c *CiscoTelemetryGNMI) newSubscribeRequest() (*gnmi.SubscribeRequest, erro
 }
 
 // SubscribeGNMI and extract telemetry data
-func (c *CiscoTelemetryGNMI) subscribeGNMI(ctx context.Context, address string, tlscfg *tls.Config, request *gnmi.SubscribeRequest) error {
-	var opt grpc.DialOption
-	if tlscfg != nil {
-		opt = grpc.WithTransportCredentials(credentials.NewTLS(tlscfg))
-	} else {
-		opt = grpc.WithInsecure()
-	}
-
-	client, err := grpc.DialContext(ctx, address, opt)
+func (c *CiscoTelemetryGNMI) subscribeGNMI(ctx context.Context, target *Target, tlscfg *tls.Config, request *gnmi.SubscribeRequest) error {
+	address := target.Address
+	client, err := c.dial(ctx, address, tlscfg)
 	if err != nil {
-		return fmt.Errorf("failed to dial: %v", err)
+		return err
 	}
 	defer client.Close()
 
+	if c.ValidateCapabilities {
+		capabilities, err := gnmi.NewGNMIClient(client).Capabilities(ctx, &gnmi.CapabilityRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to get capabilities: %w", err)
+		}
+		if err = c.validateCapabilities(capabilities, target); err != nil {
+			return fmt.Errorf("%w: subscription not supported by %s: %v", ErrInvalid, address, err)
+		}
+	}
+
 	subscribeClient, err := gnmi.NewGNMIClient(client).Subscribe(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to setup subscription: %v", err)
+		return fmt.Errorf("failed to setup subscription: %w", err)
 	}
 
 	if err = subscribeClient.Send(request); err != nil {
-		return fmt.Errorf("failed to send subscription request: %v", err)
+		return fmt.Errorf("failed to send subscription request: %w", err)
+	}
+	if aliasRequest := c.newAliasRequest(); aliasRequest != nil {
+		if err = subscribeClient.Send(aliasRequest); err != nil {
+			return fmt.Errorf("failed to send alias request: %w", err)
+		}
 	}
 
 	log.Printf("Connection to GNMI device %s established", address)
 	defer log.Printf("Connection to GNMI device %s closed", address)
+	c.setSynced(address, false)
+
+	mode := request.GetSubscribe().Mode
+	if mode == gnmi.SubscriptionList_POLL {
+		pollCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go c.sendPolls(pollCtx, subscribeClient)
+	}
+
+	connected := false
 	for ctx.Err() == nil {
 		var reply *gnmi.SubscribeResponse
 		if reply, err = subscribeClient.Recv(); err != nil {
 			if err != io.EOF && ctx.Err() == nil {
-				return fmt.Errorf("aborted GNMI subscription: %v", err)
+				return fmt.Errorf("aborted GNMI subscription: %w", err)
 			}
 			break
 		}
+		c.record(capture.GNMI, address, reply)
+
+		if err = c.handleSubscribeResponse(address, reply); err != nil {
+			return err
+		}
 
-		c.handleSubscribeResponse(address, reply)
+		// The device accepted the subscription once it sends a response that is not an error
+		if !connected {
+			connected = true
+			c.setState(address, StateConnected, nil)
+		}
+
+		// A once subscription is complete with the first sync response
+		if mode == gnmi.SubscriptionList_ONCE && reply.GetSyncResponse() {
+			break
+		}
 	}
 	return nil
 }
 
 // HandleSubscribeResponse message from GNMI and parse contained telemetry data
-func (c *CiscoTelemetryGNMI) handleSubscribeResponse(address string, reply *gnmi.SubscribeResponse) {
-	// Check if response is a GNMI Update and if we have a prefix to derive the measurement name
-	response, ok := reply.Response.(*gnmi.SubscribeResponse_Update)
-	if !ok {
-		return
+func (c *CiscoTelemetryGNMI) handleSubscribeResponse(address string, reply *gnmi.SubscribeResponse) error {
+	switch response := reply.Response.(type) {
+	case *gnmi.SubscribeResponse_Update:
+		c.handleNotification(address, response.Update)
+	case *gnmi.SubscribeResponse_SyncResponse:
+		if response.SyncResponse && !c.setSynced(address, true) {
+			log.Printf("Initial sync of GNMI device %s complete", address)
+		}
+	case *gnmi.SubscribeResponse_Error:
+		return &DeviceError{
+			Address: address,
+			Code:    codes.Code(response.Error.Code),
+			Message: response.Error.Message,
+		}
+	}
+	return nil
+}
+
+// Synced reports whether the initial data of address has been received
+func (c *CiscoTelemetryGNMI) Synced(address string) bool {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	return c.synced[address]
+}
+
+// Set the sync state of address and return the previous state
+func (c *CiscoTelemetryGNMI) setSynced(address string, synced bool) bool {
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	if c.synced == nil {
+		c.synced = make(map[string]bool)
 	}
+	previous := c.synced[address]
+	c.synced[address] = synced
+	return previous
+}
 
+// HandleNotification from a subscription or get response and write it as a measurement
+func (c *CiscoTelemetryGNMI) handleNotification(address string, notification *gnmi.Notification) {
 	var prefix, prefixAliasPath string
+	var prefixPath *gnmi.Path
 
-	timestamp := time.Unix(0, response.Update.Timestamp)
+	// Alias definition announced by the target
+	if len(notification.Alias) > 0 && notification.Prefix != nil {
+		c.addAlias(address, notification.Alias, notification.Prefix)
+	}
+
+	timestamp := time.Unix(0, notification.Timestamp)
 	prefixTags := make(map[string]string)
 
-	if response.Update.Prefix != nil {
-		prefix, prefixAliasPath = c.handlePath(response.Update.Prefix, prefixTags, "")
+	if notification.Prefix != nil {
+		prefixPath = c.resolveAlias(address, notification.Prefix)
+		prefix, prefixAliasPath = c.handlePath(prefixPath, prefixTags, "")
 	}
-	prefixTags["source"], _, _ = net.SplitHostPort(address)
+	source, _, _ := net.SplitHostPort(address)
+	prefixTags["source"] = source
 	prefixTags["path"] = prefix
+	named := newMetricGroup()
 
-	// Parse individual Update message and create measurements
-	fmt.Printf("\n\n###### New message from %v ######\n", prefixTags["source"])
-	fmt.Printf("Timestamp: %v\n", timestamp)
 	// Prepare tags from prefix
 	tags := make(map[string]string, len(prefixTags))
 	for key, val := range prefixTags {
 		tags[key] = val
 	}
-	fmt.Printf("\n\n**** TAGS *****\n")
-	for key, val := range tags {
-		fmt.Printf("%v: %v\n", key, val)
-	}
 
-	fmt.Printf("\n\n**** Values *****\n")
-	for _, update := range response.Update.Update {
-		aliasPath, fields := c.handleTelemetryField(update, tags, prefix)
+	// Parse individual Update message and group fields by measurement
+	measurements := make(map[string]map[string]interface{})
+	for _, update := range notification.Update {
+		path := joinPath(prefixPath, update.Path)
+		if subscription, rest := c.namedSubscription(address, path); subscription != nil {
+			namedTags := map[string]string{"source": source}
+			name, field := subscription.measurement(path, rest, namedTags)
+			fields := named.fields(name, namedTags, timestamp)
+			c.addTypedValue(field, update.Val, fields)
+			continue
+		}
+
+		aliasPath, updateFields := c.handleTelemetryField(update, tags, prefix)
 		// Inherent valid alias from prefix parsing
 		if len(prefixAliasPath) > 0 && len(aliasPath) == 0 {
 			aliasPath = prefixAliasPath
 		}
+		name := c.measurementName(prefix, aliasPath)
+		if _, ok := measurements[name]; !ok {
+			measurements[name] = make(map[string]interface{})
+		}
 
-		for key, val := range fields {
-			if len(aliasPath) > 0 {
+		for key, val := range updateFields {
+			if len(aliasPath) > 0 && len(key) > len(aliasPath) {
 				key = key[len(aliasPath)+1:]
 			}
-			fmt.Printf("%v: %v\n", key, val)
+			measurements[name][key] = val
+		}
+	}
+
+	for name, fields := range measurements {
+		if len(fields) == 0 {
+			continue
+		}
+		c.write(&output.Metric{
+			Name:      name,
+			Tags:      tags,
+			Fields:    fields,
+			Timestamp: timestamp,
+		})
+	}
+
+	for _, metric := range named.metrics {
+		if len(metric.Fields) > 0 {
+			c.write(metric)
 		}
+	}
+
+	// Emit a delete event for every removed path
+	for _, path := range notification.Delete {
+		if subscription, rest := c.namedSubscription(address, joinPath(prefixPath, path)); subscription != nil {
+			deleteTags := map[string]string{"source": source}
+			name, field := subscription.measurement(joinPath(prefixPath, path), rest, deleteTags)
+			c.write(&output.Metric{
+				Name:      name,
+				Tags:      deleteTags,
+				Fields:    map[string]interface{}{field: nil},
+				Timestamp: timestamp,
+				Delete:    true,
+			})
+			continue
+		}
+
+		deleteTags := make(map[string]string, len(prefixTags))
+		for key, val := range prefixTags {
+			deleteTags[key] = val
+		}
+
+		name, aliasPath := c.handlePath(path, deleteTags, prefix)
+		if len(prefixAliasPath) > 0 && len(aliasPath) == 0 {
+			aliasPath = prefixAliasPath
+		}
+		name = strings.Replace(name, "-", "_", -1)
+		if len(aliasPath) > 0 && len(name) > len(aliasPath) {
+			name = name[len(aliasPath)+1:]
+		}
+
+		c.write(&output.Metric{
+			Name:      c.measurementName(prefix, aliasPath),
+			Tags:      deleteTags,
+			Fields:    map[string]interface{}{name: nil},
+			Timestamp: timestamp,
+			Delete:    true,
+		})
+	}
+}
+
+// Measurement name, the alias name if the path is aliased or the prefix otherwise
+func (c *CiscoTelemetryGNMI) measurementName(prefix string, aliasPath string) string {
+	if len(aliasPath) > 0 {
+		if name, ok := c.aliasName(aliasPath); ok {
+			return name
+		}
+	}
+	return prefix
+}
+
+// Record a raw subscribe response to the capture file
+func (c *CiscoTelemetryGNMI) record(transport capture.Transport, address string, reply *gnmi.SubscribeResponse) {
+	if c.Recorder == nil {
+		return
+	}
+	data, err := proto.Marshal(reply)
+	if err == nil {
+		err = c.Recorder.Write(&capture.Record{
+			Time:      time.Now(),
+			Transport: transport,
+			Peer:      address,
+			Data:      data,
+		})
+	}
+	if err != nil {
+		log.Printf("failed to record subscribe response: %v", err)
+	}
+}
 
+// Write a measurement to all outputs
+func (c *CiscoTelemetryGNMI) write(metric *output.Metric) {
+	for _, out := range c.Outputs {
+		if err := out.Write(metric); err != nil {
+			log.Printf("failed to write measurement: %v", err)
+		}
 	}
 }
 
@@ -252,41 +706,9 @@ func (c *CiscoTelemetryGNMI) handleSubscribeResponse(address string, reply *gnmi
 func (c *CiscoTelemetryGNMI) handleTelemetryField(update *gnmi.Update, tags map[string]string, prefix string) (string, map[string]interface{}) {
 	path, aliasPath := c.handlePath(update.Path, tags, prefix)
 
-	var value interface{}
-	var jsondata []byte
-
-	switch val := update.Val.Value.(type) {
-	case *gnmi.TypedValue_AsciiVal:
-		value = val.AsciiVal
-	case *gnmi.TypedValue_BoolVal:
-		value = val.BoolVal
-	case *gnmi.TypedValue_BytesVal:
-		value = val.BytesVal
-	case *gnmi.TypedValue_DecimalVal:
-		value = val.DecimalVal
-	case *gnmi.TypedValue_FloatVal:
-		value = val.FloatVal
-	case *gnmi.TypedValue_IntVal:
-		value = val.IntVal
-	case *gnmi.TypedValue_StringVal:
-		value = val.StringVal
-	case *gnmi.TypedValue_UintVal:
-		value = val.UintVal
-	case *gnmi.TypedValue_JsonIetfVal:
-		jsondata = val.JsonIetfVal
-	case *gnmi.TypedValue_JsonVal:
-		jsondata = val.JsonVal
-	}
-
 	name := strings.Replace(path, "-", "_", -1)
 	fields := make(map[string]interface{})
-	if value != nil {
-		fields[name] = value
-	} else if jsondata != nil {
-		if err := json.Unmarshal(jsondata, &value); err != nil {
-			log.Printf("failed to parse JSON value: %v", err)
-		}
-	}
+	c.addTypedValue(name, update.Val, fields)
 	return aliasPath, fields
 }
 
@@ -307,6 +729,11 @@ func (c *CiscoTelemetryGNMI) handlePath(path *gnmi.Path, tags map[string]string,
 		builder.WriteString(elem.Name)
 		name := builder.String()
 
+		// Use the longest aliased path
+		if _, ok := c.aliasName(name); ok {
+			aliasPath = name
+		}
+
 		if tags != nil {
 			for key, val := range elem.Key {
 				key = strings.Replace(key, "-", "_", -1)
@@ -325,77 +752,81 @@ func (c *CiscoTelemetryGNMI) handlePath(path *gnmi.Path, tags map[string]string,
 	return builder.String(), aliasPath
 }
 
-//ParsePath from XPath-like string to GNMI path structure
+// ParsePath from XPath-like string to GNMI path structure, the origin is
+// used unless the path string starts with its own origin
 func parsePath(origin string, path string, target string) (*gnmi.Path, error) {
-	var err error
-	gnmiPath := gnmi.Path{Origin: origin, Target: target}
-
-	if len(path) > 0 && path[0] != '/' {
-		return nil, fmt.Errorf("path does not start with a '/': %s", path)
+	gnmiPath, err := gnmipath.Parse(path)
+	if err != nil {
+		return nil, err
 	}
 
-	elem := &gnmi.PathElem{}
-	start, name, value, end := 0, -1, -1, -1
-
-	path = path + "/"
-
-	for i := 0; i < len(path); i++ {
-		if path[i] == '[' {
-			if name >= 0 {
-				break
-			}
-			if end < 0 {
-				end = i
-				elem.Key = make(map[string]string)
-			}
-			name = i + 1
-		} else if path[i] == '=' {
-			if name <= 0 || value >= 0 {
-				break
-			}
-			value = i + 1
-		} else if path[i] == ']' {
-			if name <= 0 || value <= name {
-				break
-			}
-			elem.Key[path[name:value-1]] = strings.Trim(path[value:i], "'\"")
-			name, value = -1, -1
-		} else if path[i] == '/' {
-			if name < 0 {
-				if end < 0 {
-					end = i
-				}
+	if len(gnmiPath.Origin) == 0 {
+		gnmiPath.Origin = origin
+	}
+	gnmiPath.Target = target
+	return gnmiPath, nil
+}
 
-				if end > start {
-					elem.Name = path[start:end]
-					gnmiPath.Elem = append(gnmiPath.Elem, elem)
-					gnmiPath.Element = append(gnmiPath.Element, path[start:i])
-				}
+// Stop all subscriptions, wait for them to end and flush the outputs
+func (c *CiscoTelemetryGNMI) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	c.wg.Wait()
+	c.flush()
+}
 
-				start, name, value, end = i+1, -1, -1, -1
-				elem = &gnmi.PathElem{}
-			}
+// Flush all outputs
+func (c *CiscoTelemetryGNMI) flush() {
+	for _, out := range c.Outputs {
+		if err := out.Flush(); err != nil {
+			log.Printf("failed to flush output: %v", err)
 		}
 	}
+}
 
-	if name >= 0 || value >= 0 {
-		err = fmt.Errorf("Invalid GNMI path: %s", path)
-	}
+// Repeatable string flag
+type stringList []string
 
-	if err != nil {
-		return nil, err
-	}
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
 
-	return &gnmiPath, nil
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
 
-// Stop listener and cleanup
-func (c *CiscoTelemetryGNMI) Stop() {
-	c.cancel()
-	c.wg.Wait()
+var (
+	validate     = flag.Bool("validate", false, "Check subscription origins and encoding against the device capabilities before subscribing")
+	dataType     = flag.String("type", "all", "Data type for get: all, config, state or operational")
+	encoding     = flag.String("encoding", "json_ietf", "Encoding for get and set")
+	mode         = flag.String("mode", "stream", "Subscription list mode: stream, once or poll")
+	pollInterval = flag.Duration("poll-interval", 0, "Interval between polls in poll mode, polls on Enter if zero")
+	listen       = flag.String("listen", ":57400", "Listen address of the dial-out server")
+	statusAddr   = flag.String("status", "", "Listen address of the HTTP status endpoint, e.g. :8080")
+	record       = flag.String("record", "", "Record the raw subscribe responses to this capture file")
+	tlsCert      = flag.String("tls-cert", "", "Certificate file of the dial-out server, plain text if empty")
+	tlsKey       = flag.String("tls-key", "", "Key file of the dial-out server")
+
+	getPaths, deletes, replaces, updates stringList
+)
+
+func init() {
+	flag.Var(&getPaths, "path", "Path to get as [origin:]/path, can be repeated")
+	flag.Var(&deletes, "delete", "Path to delete on set, can be repeated")
+	flag.Var(&replaces, "replace", "Replace on set as path=file with a JSON or YAML file, can be repeated")
+	flag.Var(&updates, "update", "Update on set as path=file with a JSON or YAML file, can be repeated")
 }
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [subscribe|capabilities|get|set|dialout|replay <capture>]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	subscriptions := []Subscription{}
 	addresses := []string{}
 
@@ -425,10 +856,60 @@ func main() {
 		Password:      "YOURPASSWORD",
 		Addresses:     addresses,
 		Subscriptions: subscriptions,
+
+		ValidateCapabilities: *validate,
+
+		Mode:         *mode,
+		PollInterval: *pollInterval,
+
+		StatusAddress:  *statusAddr,
+		DialOutAddress: *listen,
+		DialOutTLSCert: *tlsCert,
+		DialOutTLSKey:  *tlsKey,
+	}
+
+	var err error
+	if len(*record) > 0 {
+		if gnmiCollector.Recorder, err = capture.Create(*record); err != nil {
+			log.Fatalf("Failed to create capture file: %v", err)
+		}
+		defer gnmiCollector.Recorder.Close()
 	}
 
-	err := gnmiCollector.Start()
+	switch command := flag.Arg(0); command {
+	case "", "subscribe":
+		if strings.ToLower(gnmiCollector.Mode) == "poll" && gnmiCollector.PollInterval == 0 {
+			go pollOnEnter(&gnmiCollector)
+		}
+		err = gnmiCollector.Run(context.Background())
+	case "capabilities":
+		err = gnmiCollector.Capabilities(context.Background())
+	case "get":
+		err = gnmiCollector.Get(context.Background(), getPaths, *dataType, *encoding)
+	case "set":
+		err = gnmiCollector.Set(context.Background(), deletes, replaces, updates, *encoding)
+	case "dialout":
+		err = gnmiCollector.RunDialOut(context.Background())
+	case "replay":
+		var reader *capture.Reader
+		if reader, err = capture.Open(flag.Arg(1)); err == nil {
+			defer reader.Close()
+			err = gnmiCollector.Replay(reader)
+		}
+	default:
+		flag.Usage()
+		err = fmt.Errorf("unknown command %s", command)
+	}
 	if err != nil {
-		log.Printf("Error starting gnmi Collector: %v\n", err)
+		log.Printf("Error running gnmi Collector: %v\n", err)
+	}
+}
+
+// Poll every time Enter is pressed
+func pollOnEnter(c *CiscoTelemetryGNMI) {
+	fmt.Printf("\nPress Enter to poll\n")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		c.Poll()
 	}
 }
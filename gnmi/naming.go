@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Subscription with measurement naming or tag mapping and its full path including the prefix
+type namedSubscription struct {
+	*Subscription
+	path *gnmi.Path
+}
+
+// Build the full paths of the subscriptions with measurement naming or tag
+// mapping per target, the collector subscriptions apply to unknown addresses
+func (c *CiscoTelemetryGNMI) initNaming(targets []*Target) error {
+	c.named = make(map[string][]namedSubscription, len(targets)+1)
+	for _, target := range append([]*Target{c.resolveTarget(Target{})}, targets...) {
+		prefix, err := parsePath(target.Origin, target.Prefix, "")
+		if err != nil {
+			return err
+		}
+
+		for i := range target.Subscriptions {
+			subscription := &target.Subscriptions[i]
+			if len(subscription.Name) == 0 && len(subscription.TagKeys) == 0 &&
+				len(subscription.TagRenames) == 0 && !subscription.IncludeOrigin {
+				continue
+			}
+
+			path, err := parsePath(subscription.Origin, subscription.Path, "")
+			if err != nil {
+				return fmt.Errorf("invalid subscription path %s: %v", subscription.Path, err)
+			}
+			if len(path.Origin) == 0 {
+				path.Origin = prefix.Origin
+			}
+			path.Elem = append(append([]*gnmi.PathElem{}, prefix.Elem...), path.Elem...)
+			c.named[target.Address] = append(c.named[target.Address], namedSubscription{Subscription: subscription, path: path})
+		}
+	}
+	return nil
+}
+
+// Find the most specific named subscription of a path received from address
+// and return it with the remaining path below the subscription path, nil if there is none
+func (c *CiscoTelemetryGNMI) namedSubscription(address string, path *gnmi.Path) (*namedSubscription, *gnmi.Path) {
+	named, ok := c.named[address]
+	if !ok {
+		named = c.named[""]
+	}
+
+	var match *namedSubscription
+	var rest *gnmi.Path
+	for i := range named {
+		candidate := path
+		// Targets may leave out the origin of the subscription
+		if len(path.Origin) == 0 {
+			candidate = &gnmi.Path{Origin: named[i].path.Origin, Elem: path.Elem}
+		}
+		if r, ok := gnmipath.TrimPrefix(named[i].path, candidate); ok && (rest == nil || len(r.Elem) < len(rest.Elem)) {
+			match, rest = &named[i], r
+		}
+	}
+	return match, rest
+}
+
+// Measurement name and field name of a path under the subscription, adds the selected keys to tags
+func (n *namedSubscription) measurement(path *gnmi.Path, rest *gnmi.Path, tags map[string]string) (string, string) {
+	name := n.Name
+	if len(name) == 0 {
+		name = elemNames(n.path.Elem)
+		if n.IncludeOrigin && len(n.path.Origin) > 0 {
+			name = n.path.Origin + ":" + name
+		}
+	}
+	if n.IncludeOrigin && len(path.Origin) > 0 {
+		tags["origin"] = path.Origin
+	}
+
+	for _, elem := range path.Elem {
+		for key, val := range elem.Key {
+			qualified := elem.Name + "/" + key
+			if len(n.TagKeys) > 0 && !contains(n.TagKeys, key) && !contains(n.TagKeys, qualified) {
+				continue
+			}
+
+			tag, ok := n.TagRenames[qualified]
+			if !ok {
+				tag, ok = n.TagRenames[key]
+			}
+			if !ok {
+				// Qualify the key with its element name on collisions
+				tag = strings.Replace(key, "-", "_", -1)
+				if _, exists := tags[tag]; exists {
+					tag = strings.Replace(qualified, "-", "_", -1)
+				}
+			}
+			tags[tag] = val
+		}
+	}
+
+	field := elemNames(rest.Elem)
+	if len(field) == 0 && len(path.Elem) > 0 {
+		field = elemNames(path.Elem[len(path.Elem)-1:])
+	}
+	return name, field
+}
+
+// Join the element names of a path without keys
+func elemNames(elems []*gnmi.PathElem) string {
+	names := make([]string, len(elems))
+	for i, elem := range elems {
+		names[i] = strings.Replace(elem.Name, "-", "_", -1)
+	}
+	return strings.Join(names, "/")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Measurements of a notification grouped by name and tags
+type metricGroup struct {
+	metrics map[string]*output.Metric
+}
+
+func newMetricGroup() *metricGroup {
+	return &metricGroup{metrics: make(map[string]*output.Metric)}
+}
+
+// Fields of the measurement with name and tags, created if it does not exist yet
+func (g *metricGroup) fields(name string, tags map[string]string, timestamp time.Time) map[string]interface{} {
+	keys := make([]string, 0, len(tags))
+	for key, val := range tags {
+		keys = append(keys, key+"="+val)
+	}
+	sort.Strings(keys)
+	id := name + "," + strings.Join(keys, ",")
+
+	metric, ok := g.metrics[id]
+	if !ok {
+		metric = &output.Metric{
+			Name:      name,
+			Tags:      tags,
+			Fields:    make(map[string]interface{}),
+			Timestamp: timestamp,
+		}
+		g.metrics[id] = metric
+	}
+	return metric.Fields
+}
+
+// Join a prefix and a path, the origin of the prefix takes precedence
+func joinPath(prefix *gnmi.Path, path *gnmi.Path) *gnmi.Path {
+	joined := &gnmi.Path{Origin: prefix.GetOrigin(), Elem: append(append([]*gnmi.PathElem{}, prefix.GetElem()...), path.GetElem()...)}
+	if len(joined.Origin) == 0 {
+		joined.Origin = path.GetOrigin()
+	}
+	return joined
+}
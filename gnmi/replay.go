@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io"
+	"log"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Replay decodes the subscribe responses of a capture with the naming and aliases
+// of the configuration, as if they were received from the recorded devices.
+// Records of other transports are skipped.
+func (c *CiscoTelemetryGNMI) Replay(reader *capture.Reader) error {
+	targets, err := c.targets()
+	if err != nil {
+		return err
+	} else if err = c.initAliases(); err != nil {
+		return err
+	} else if err = c.initNaming(targets); err != nil {
+		return err
+	}
+
+	c.setDefaults()
+	defer c.flush()
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if record.Transport != capture.GNMI && record.Transport != capture.GNMIDialOut {
+			continue
+		}
+
+		response := &gnmi.SubscribeResponse{}
+		if err := proto.Unmarshal(record.Data, response); err != nil {
+			log.Printf("Skipping invalid subscribe response from %s: %v", record.Peer, err)
+			continue
+		}
+		if err := c.handleSubscribeResponse(record.Peer, response); err != nil {
+			log.Printf("Recorded error: %v", err)
+		}
+	}
+}
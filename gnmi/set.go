@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Set applies the deletes, replaces and updates on every address. Replaces
+// and updates are given as path=file, files with a .yaml or .yml extension
+// are read as YAML and everything else as JSON.
+func (c *CiscoTelemetryGNMI) Set(ctx context.Context, deletes, replaces, updates []string, encoding string) error {
+	request, err := c.newSetRequest(deletes, replaces, updates, encoding)
+	if err != nil {
+		return err
+	}
+
+	targets, err := c.targets()
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, target := range targets {
+		if err := c.set(target.withCredentials(ctx), target, request); err != nil {
+			log.Printf("Set on %s failed: %v", target.Address, err)
+			failed = append(failed, target.Address)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("set failed for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Create a new GNMI SetRequest
+func (c *CiscoTelemetryGNMI) newSetRequest(deletes, replaces, updates []string, encoding string) (*gnmi.SetRequest, error) {
+	if len(deletes)+len(replaces)+len(updates) == 0 {
+		return nil, fmt.Errorf("nothing to set")
+	}
+
+	enc, ok := gnmi.Encoding_value[strings.ToUpper(encoding)]
+	if !ok {
+		return nil, fmt.Errorf("invalid encoding %s", encoding)
+	}
+
+	request := &gnmi.SetRequest{}
+
+	for _, path := range deletes {
+		gnmiPath, err := c.parseOriginPath(path)
+		if err != nil {
+			return nil, err
+		}
+		request.Delete = append(request.Delete, gnmiPath)
+	}
+	for _, arg := range replaces {
+		update, err := c.newSetUpdate(arg, gnmi.Encoding(enc))
+		if err != nil {
+			return nil, err
+		}
+		request.Replace = append(request.Replace, update)
+	}
+	for _, arg := range updates {
+		update, err := c.newSetUpdate(arg, gnmi.Encoding(enc))
+		if err != nil {
+			return nil, err
+		}
+		request.Update = append(request.Update, update)
+	}
+	return request, nil
+}
+
+// Create an update from a path=file argument
+func (c *CiscoTelemetryGNMI) newSetUpdate(arg string, encoding gnmi.Encoding) (*gnmi.Update, error) {
+	i := strings.LastIndex(arg, "=")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid update %s, expected path=file", arg)
+	}
+
+	gnmiPath, err := c.parseOriginPath(arg[:i])
+	if err != nil {
+		return nil, err
+	}
+	data, err := readValueFile(arg[i+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	var value *gnmi.TypedValue
+	switch encoding {
+	case gnmi.Encoding_JSON:
+		value = &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: data}}
+	case gnmi.Encoding_JSON_IETF:
+		value = &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: data}}
+	default:
+		return nil, fmt.Errorf("unsupported set encoding %s", encoding)
+	}
+	return &gnmi.Update{Path: gnmiPath, Val: value}, nil
+}
+
+// Read a JSON or YAML file and return its content as JSON
+func readValueFile(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("invalid YAML in %s: %v", file, err)
+		}
+	default:
+		if !json.Valid(data) {
+			return nil, fmt.Errorf("invalid JSON in %s", file)
+		}
+	}
+	return data, nil
+}
+
+// Dial a device, apply the set request and print the results
+func (c *CiscoTelemetryGNMI) set(ctx context.Context, target *Target, request *gnmi.SetRequest) error {
+	address := target.Address
+	tlscfg, err := target.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	// The prefix carries the origin, prefix and target name of the device
+	prefix, err := parsePath(target.Origin, target.Prefix, target.Target)
+	if err != nil {
+		return err
+	}
+	request = proto.Clone(request).(*gnmi.SetRequest)
+	request.Prefix = prefix
+
+	client, err := c.dial(ctx, address, tlscfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	response, err := gnmi.NewGNMIClient(client).Set(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to set: %v", err)
+	}
+
+	fmt.Printf("\n###### Set on %v ######\n", address)
+	fmt.Printf("Timestamp: %v\n", time.Unix(0, response.Timestamp))
+	for _, result := range response.Response {
+		fmt.Printf("%v: %v\n", result.Op, gnmipath.Format(result.Path))
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+/*
+Package simulator implements a gNMI target serving a tree of paths and
+values, to test the gNMI collector without a device. It supports
+Capabilities, Get and Subscribe in all modes and can be scripted to drop
+connections or fail requests.
+
+	target := simulator.NewTarget()
+	target.Update("openconfig-interfaces:/interfaces/interface[name=eth0]/state/counters/in-octets", uint64(10))
+	address, err := target.Start("127.0.0.1:0")
+	...
+	target.Stop()
+*/
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/gnmi/gnmipath"
+	"github.com/CiscoSE/grpc_collector/internal/grpcauth"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Target is an in-process gNMI target, it is safe for concurrent use
+type Target struct {
+	// Credentials required in the request metadata, not checked if Username is empty
+	Username string
+	Password string
+
+	// Models announced by Capabilities, one model per origin of the tree if empty
+	Models []*gnmi.ModelData
+
+	// Sample interval of TARGET_DEFINED subscriptions and of SAMPLE
+	// subscriptions without interval, one second if zero
+	SampleInterval time.Duration
+
+	mu      sync.Mutex
+	leaves  map[string]*leaf
+	streams map[*stream]struct{}
+	version uint64
+
+	// Scripted failures of the next RPCs
+	failures int
+	failure  error
+
+	connections int
+	server      *grpc.Server
+}
+
+// Leaf of the tree
+type leaf struct {
+	path      *gnmi.Path
+	value     *gnmi.TypedValue
+	timestamp int64
+
+	// Incremented whenever the value changes
+	version uint64
+}
+
+// NewTarget returns a target with an empty tree
+func NewTarget() *Target {
+	return &Target{
+		leaves:  make(map[string]*leaf),
+		streams: make(map[*stream]struct{}),
+	}
+}
+
+// Update the value of a leaf, value is a *gnmi.TypedValue or a Go value, see
+// TypedValue. Streams subscribed on change are notified.
+func (t *Target) Update(path string, value interface{}) error {
+	gnmiPath, err := gnmipath.Parse(path)
+	if err != nil {
+		return err
+	}
+	if len(gnmiPath.Elem) == 0 {
+		return fmt.Errorf("cannot set root path")
+	}
+	val, err := TypedValue(value)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	key := gnmipath.Format(gnmiPath)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l, ok := t.leaves[key]
+	if !ok {
+		l = &leaf{path: gnmiPath}
+		t.leaves[key] = l
+	}
+	l.timestamp = time.Now().UnixNano()
+	if !ok || l.value.String() != val.String() {
+		t.version++
+		l.value = val
+		l.version = t.version
+		t.notify(key, l.path)
+	}
+	return nil
+}
+
+// Load updates all values of a map from path to value
+func (t *Target) Load(values map[string]interface{}) error {
+	for path, value := range values {
+		if err := t.Update(path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete all leaves at or below path, streams subscribed on change are notified
+func (t *Target) Delete(path string) error {
+	pattern, err := gnmipath.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, l := range t.leaves {
+		if matches(pattern, l.path) {
+			delete(t.leaves, key)
+			t.notify(key, l.path)
+		}
+	}
+	return nil
+}
+
+// Increment all unsigned and signed integer leaves at or below path by delta, to emulate counters
+func (t *Target) Increment(path string, delta int64) error {
+	pattern, err := gnmipath.Parse(path)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now().UnixNano()
+	for key, l := range t.leaves {
+		if !matches(pattern, l.path) {
+			continue
+		}
+		switch val := l.value.Value.(type) {
+		case *gnmi.TypedValue_UintVal:
+			l.value = &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: val.UintVal + uint64(delta)}}
+		case *gnmi.TypedValue_IntVal:
+			l.value = &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: val.IntVal + delta}}
+		default:
+			continue
+		}
+		t.version++
+		l.version = t.version
+		l.timestamp = now
+		t.notify(key, l.path)
+	}
+	return nil
+}
+
+// FailNext makes the next count RPCs fail with code and message, for example
+// codes.Unauthenticated to emulate rejected credentials
+func (t *Target) FailNext(count int, code codes.Code, message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures = count
+	t.failure = status.Error(code, message)
+}
+
+// Drop all active subscriptions with codes.Unavailable, as if the connection was lost
+func (t *Target) Drop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for s := range t.streams {
+		s.dropOnce.Do(func() { close(s.drop) })
+	}
+}
+
+// Connections returns the number of subscriptions accepted so far
+func (t *Target) Connections() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connections
+}
+
+// Start serving on address and return the address listened on, use port 0 to pick a free port
+func (t *Target) Start(address string, opts ...grpc.ServerOption) (string, error) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", err
+	}
+	server := grpc.NewServer(opts...)
+	gnmi.RegisterGNMIServer(server, t)
+
+	t.mu.Lock()
+	t.server = server
+	t.mu.Unlock()
+
+	go server.Serve(lis)
+	return lis.Addr().String(), nil
+}
+
+// Serve on lis until the listener fails or Stop is called
+func (t *Target) Serve(lis net.Listener, opts ...grpc.ServerOption) error {
+	server := grpc.NewServer(opts...)
+	gnmi.RegisterGNMIServer(server, t)
+
+	t.mu.Lock()
+	t.server = server
+	t.mu.Unlock()
+
+	return server.Serve(lis)
+}
+
+// Stop the server and close all connections, the tree is kept so the target can be started again
+func (t *Target) Stop() {
+	t.mu.Lock()
+	server := t.server
+	t.server = nil
+	t.mu.Unlock()
+
+	if server != nil {
+		server.Stop()
+	}
+}
+
+// Capabilities returns the models of the target and the supported encodings
+func (t *Target) Capabilities(ctx context.Context, request *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	if err := t.check(ctx); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	models := t.Models
+	if len(models) == 0 {
+		origins := make(map[string]bool)
+		for _, l := range t.leaves {
+			if len(l.path.Origin) > 0 && !origins[l.path.Origin] {
+				origins[l.path.Origin] = true
+				models = append(models, &gnmi.ModelData{Name: l.path.Origin, Organization: "simulator"})
+			}
+		}
+		sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	}
+
+	return &gnmi.CapabilityResponse{
+		SupportedModels:    models,
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF, gnmi.Encoding_PROTO},
+		GNMIVersion:        "0.7.0",
+	}, nil
+}
+
+// Get returns one notification per container of the leaves below the requested paths
+func (t *Target) Get(ctx context.Context, request *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	if err := t.check(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkEncoding(request.Encoding); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	response := &gnmi.GetResponse{}
+	for _, path := range request.Path {
+		pattern := join(request.Prefix, path)
+		var leaves []*leaf
+		for _, l := range t.leaves {
+			if matches(pattern, l.path) {
+				leaves = append(leaves, l)
+			}
+		}
+		if len(leaves) == 0 {
+			return nil, status.Errorf(codes.NotFound, "path %s not found", gnmipath.Format(pattern))
+		}
+		notifications, err := notifications(leaves, request.Prefix.GetTarget(), request.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		response.Notification = append(response.Notification, notifications...)
+	}
+	return response, nil
+}
+
+// Set is not supported
+func (t *Target) Set(ctx context.Context, request *gnmi.SetRequest) (*gnmi.SetResponse, error) {
+	if err := t.check(ctx); err != nil {
+		return nil, err
+	}
+	return nil, status.Error(codes.Unimplemented, "set is not supported by the simulator")
+}
+
+// Check scripted failures and credentials of a request
+func (t *Target) check(ctx context.Context) error {
+	t.mu.Lock()
+	if t.failures > 0 {
+		t.failures--
+		t.mu.Unlock()
+		return t.failure
+	}
+	t.mu.Unlock()
+
+	return grpcauth.Check(ctx, t.Username, t.Password)
+}
+
+// Only encodings the values can be converted to are supported
+func checkEncoding(encoding gnmi.Encoding) error {
+	switch encoding {
+	case gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF, gnmi.Encoding_PROTO:
+		return nil
+	}
+	return status.Errorf(codes.Unimplemented, "unsupported encoding %v", encoding)
+}
+
+// Report a changed leaf to all streams, t.mu must be held
+func (t *Target) notify(key string, path *gnmi.Path) {
+	for s := range t.streams {
+		s.changed(key, path)
+	}
+}
+
+// Join a request prefix and path into the pattern to match leaves against
+func join(prefix *gnmi.Path, path *gnmi.Path) *gnmi.Path {
+	joined := &gnmi.Path{Origin: path.GetOrigin()}
+	if len(joined.Origin) == 0 {
+		joined.Origin = prefix.GetOrigin()
+	}
+	joined.Elem = append(joined.Elem, prefix.GetElem()...)
+	joined.Elem = append(joined.Elem, path.GetElem()...)
+	return joined
+}
+
+// Match a leaf at or below pattern, origins are only compared if both are set
+func matches(pattern *gnmi.Path, path *gnmi.Path) bool {
+	if len(pattern.Origin) > 0 && len(path.Origin) > 0 && pattern.Origin != path.Origin {
+		return false
+	}
+	return gnmipath.MatchPrefix(&gnmi.Path{Elem: pattern.Elem}, &gnmi.Path{Elem: path.Elem})
+}
+
+// Group leaves into one notification per container like a device does, the
+// prefix is the container and the updates are named after the leaves
+func notifications(leaves []*leaf, target string, encoding gnmi.Encoding) ([]*gnmi.Notification, error) {
+	sort.Slice(leaves, func(i, j int) bool {
+		return gnmipath.Format(leaves[i].path) < gnmipath.Format(leaves[j].path)
+	})
+
+	var result []*gnmi.Notification
+	byPrefix := make(map[string]*gnmi.Notification)
+	for _, l := range leaves {
+		prefix, name := split(l.path, target)
+		key := gnmipath.Format(prefix)
+		notification, ok := byPrefix[key]
+		if !ok {
+			notification = &gnmi.Notification{Prefix: prefix}
+			byPrefix[key] = notification
+			result = append(result, notification)
+		}
+		if l.timestamp > notification.Timestamp {
+			notification.Timestamp = l.timestamp
+		}
+
+		value, err := encode(l.value, encoding)
+		if err != nil {
+			return nil, err
+		}
+		notification.Update = append(notification.Update, &gnmi.Update{Path: name, Val: value})
+	}
+	return result, nil
+}
+
+// Split a leaf path into its container as prefix and its name
+func split(path *gnmi.Path, target string) (*gnmi.Path, *gnmi.Path) {
+	last := len(path.Elem) - 1
+	prefix := &gnmi.Path{Origin: path.Origin, Elem: path.Elem[:last], Target: target}
+	return prefix, &gnmi.Path{Elem: path.Elem[last:]}
+}
@@ -0,0 +1,320 @@
+package simulator
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Active subscribe stream
+type stream struct {
+	drop     chan struct{}
+	dropOnce sync.Once
+
+	// Leaves changed since the stream last looked by key, signaled on notify
+	mu      sync.Mutex
+	pending map[string]*gnmi.Path
+	notify  chan struct{}
+}
+
+// Record a changed leaf and wake up the stream
+func (s *stream) changed(key string, path *gnmi.Path) {
+	s.mu.Lock()
+	s.pending[key] = path
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Take the changed leaves
+func (s *stream) changes() map[string]*gnmi.Path {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.pending
+	s.pending = make(map[string]*gnmi.Path)
+	return pending
+}
+
+// Subscription of a stream
+type subscription struct {
+	pattern *gnmi.Path
+
+	// Sample and heartbeat intervals with their next due time, zero if not used
+	sample, heartbeat           time.Duration
+	nextSample, nextHeartbeat   time.Time
+	suppressRedundant, onChange bool
+
+	// Version of every leaf last sent
+	sent map[string]uint64
+}
+
+// Subscribe serves ONCE, POLL and STREAM subscriptions. Streamed SAMPLE and
+// TARGET_DEFINED subscriptions are sampled, ON_CHANGE subscriptions get an
+// update on every change. Heartbeats resend all leaves of a subscription.
+func (t *Target) Subscribe(server gnmi.GNMI_SubscribeServer) error {
+	ctx := server.Context()
+	if err := t.check(ctx); err != nil {
+		return err
+	}
+
+	request, err := server.Recv()
+	if err != nil {
+		return err
+	}
+	list := request.GetSubscribe()
+	if list == nil {
+		return status.Error(codes.InvalidArgument, "first request must be a subscription list")
+	}
+	if err = checkEncoding(list.Encoding); err != nil {
+		return err
+	}
+
+	s := &stream{
+		drop:    make(chan struct{}),
+		pending: make(map[string]*gnmi.Path),
+		notify:  make(chan struct{}, 1),
+	}
+	t.mu.Lock()
+	t.streams[s] = struct{}{}
+	t.connections++
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.streams, s)
+		t.mu.Unlock()
+	}()
+
+	now := time.Now()
+	subscriptions := make([]*subscription, len(list.Subscription))
+	for i, sub := range list.Subscription {
+		subscriptions[i] = t.newSubscription(list, sub, now)
+	}
+
+	// Poll requests are read in the background, the stream ends when the client closes it
+	polls := make(chan struct{})
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			request, err := server.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			if request.GetPoll() != nil {
+				select {
+				case polls <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	// Initial updates followed by the sync response
+	if !list.UpdatesOnly || list.Mode != gnmi.SubscriptionList_STREAM {
+		for _, sub := range subscriptions {
+			if err = t.send(server, list, sub, nil, false); err != nil {
+				return err
+			}
+		}
+	}
+	if err = syncResponse(server); err != nil {
+		return err
+	}
+	if list.Mode == gnmi.SubscriptionList_ONCE {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		var due time.Time
+		if list.Mode == gnmi.SubscriptionList_STREAM {
+			due = nextDue(subscriptions)
+		}
+		if !due.IsZero() {
+			timer.Reset(time.Until(due))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.drop:
+			return status.Error(codes.Unavailable, "connection dropped by the simulator")
+		case err = <-recvErr:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		case <-polls:
+			for _, sub := range subscriptions {
+				if err = t.send(server, list, sub, nil, false); err != nil {
+					return err
+				}
+			}
+			err = syncResponse(server)
+		case <-s.notify:
+			if list.Mode != gnmi.SubscriptionList_STREAM {
+				s.changes()
+				continue
+			}
+			changed := s.changes()
+			for _, sub := range subscriptions {
+				if sub.onChange {
+					if err = t.send(server, list, sub, changed, true); err != nil {
+						return err
+					}
+				}
+			}
+		case <-timer.C:
+			now := time.Now()
+			for _, sub := range subscriptions {
+				if err = t.sendDue(server, list, sub, now); err != nil {
+					return err
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		// Drain the timer so Reset starts from a stopped timer
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+	}
+}
+
+// Create the state of a subscription from the request
+func (t *Target) newSubscription(list *gnmi.SubscriptionList, sub *gnmi.Subscription, now time.Time) *subscription {
+	sampleInterval := t.SampleInterval
+	if sampleInterval <= 0 {
+		sampleInterval = time.Second
+	}
+
+	s := &subscription{
+		pattern:           join(list.Prefix, sub.Path),
+		heartbeat:         time.Duration(sub.HeartbeatInterval),
+		suppressRedundant: sub.SuppressRedundant,
+		sent:              make(map[string]uint64),
+	}
+	switch sub.Mode {
+	case gnmi.SubscriptionMode_ON_CHANGE:
+		s.onChange = true
+	case gnmi.SubscriptionMode_SAMPLE:
+		s.sample = time.Duration(sub.SampleInterval)
+		if s.sample <= 0 {
+			s.sample = sampleInterval
+		}
+	default:
+		s.sample = sampleInterval
+	}
+
+	if s.sample > 0 {
+		s.nextSample = now.Add(s.sample)
+	}
+	if s.heartbeat > 0 {
+		s.nextHeartbeat = now.Add(s.heartbeat)
+	}
+	return s
+}
+
+// Earliest due time of all samples and heartbeats, zero if none is scheduled
+func nextDue(subscriptions []*subscription) time.Time {
+	var due time.Time
+	for _, sub := range subscriptions {
+		for _, next := range []time.Time{sub.nextSample, sub.nextHeartbeat} {
+			if !next.IsZero() && (due.IsZero() || next.Before(due)) {
+				due = next
+			}
+		}
+	}
+	return due
+}
+
+// Send a due heartbeat or sample, a heartbeat sends all leaves even if
+// redundant samples are suppressed
+func (t *Target) sendDue(server gnmi.GNMI_SubscribeServer, list *gnmi.SubscriptionList, sub *subscription, now time.Time) error {
+	heartbeat := !sub.nextHeartbeat.IsZero() && !now.Before(sub.nextHeartbeat)
+	sample := !sub.nextSample.IsZero() && !now.Before(sub.nextSample)
+	if heartbeat {
+		sub.nextHeartbeat = now.Add(sub.heartbeat)
+	}
+	if sample {
+		sub.nextSample = now.Add(sub.sample)
+	}
+
+	switch {
+	case heartbeat:
+		return t.send(server, list, sub, nil, false)
+	case sample:
+		return t.send(server, list, sub, nil, sub.suppressRedundant)
+	}
+	return nil
+}
+
+// Send the leaves of a subscription. If changed is set only these leaves are
+// considered and deleted ones are reported, if onlyChanged is set leaves
+// already sent with the same version are skipped.
+func (t *Target) send(server gnmi.GNMI_SubscribeServer, list *gnmi.SubscriptionList, sub *subscription, changed map[string]*gnmi.Path, onlyChanged bool) error {
+	var leaves []*leaf
+	var deleted []*gnmi.Path
+
+	t.mu.Lock()
+	if changed == nil {
+		for key, l := range t.leaves {
+			if matches(sub.pattern, l.path) && (!onlyChanged || sub.sent[key] != l.version) {
+				leaves = append(leaves, l)
+				sub.sent[key] = l.version
+			}
+		}
+	} else {
+		for key, path := range changed {
+			if l, ok := t.leaves[key]; ok {
+				if matches(sub.pattern, l.path) && (!onlyChanged || sub.sent[key] != l.version) {
+					leaves = append(leaves, l)
+					sub.sent[key] = l.version
+				}
+			} else if _, ok := sub.sent[key]; ok {
+				delete(sub.sent, key)
+				deleted = append(deleted, path)
+			}
+		}
+	}
+	notifications, err := notifications(leaves, list.Prefix.GetTarget(), list.Encoding)
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range deleted {
+		prefix, name := split(path, list.Prefix.GetTarget())
+		notifications = append(notifications, &gnmi.Notification{
+			Timestamp: time.Now().UnixNano(),
+			Prefix:    prefix,
+			Delete:    []*gnmi.Path{name},
+		})
+	}
+
+	for _, notification := range notifications {
+		response := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: notification}}
+		if err = server.Send(response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syncResponse(server gnmi.GNMI_SubscribeServer) error {
+	return server.Send(&gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
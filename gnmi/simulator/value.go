@@ -0,0 +1,94 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// TypedValue converts a Go value into a gNMI value. Strings, booleans, byte
+// slices, integers and floats map to the matching scalar, integral float64
+// values as decoded from JSON or YAML map to uint or int, and maps and slices
+// are encoded as JSON_IETF.
+func TypedValue(value interface{}) (*gnmi.TypedValue, error) {
+	switch v := value.(type) {
+	case *gnmi.TypedValue:
+		return v, nil
+	case string:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: v}}, nil
+	case bool:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: v}}, nil
+	case []byte:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BytesVal{BytesVal: v}}, nil
+	case int:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int32:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: v}}, nil
+	case uint:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint32:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: v}}, nil
+	case float32:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_FloatVal{FloatVal: v}}, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			if v >= 0 {
+				return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+			}
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+		}
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_FloatVal{FloatVal: float32(v)}}, nil
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: data}}, nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// Encode a value for a request encoding, scalars are sent as JSON for the JSON encodings
+func encode(value *gnmi.TypedValue, encoding gnmi.Encoding) (*gnmi.TypedValue, error) {
+	if encoding == gnmi.Encoding_PROTO {
+		return value, nil
+	}
+
+	var scalar interface{}
+	switch v := value.Value.(type) {
+	case *gnmi.TypedValue_JsonVal:
+		scalar = json.RawMessage(v.JsonVal)
+	case *gnmi.TypedValue_JsonIetfVal:
+		scalar = json.RawMessage(v.JsonIetfVal)
+	case *gnmi.TypedValue_StringVal:
+		scalar = v.StringVal
+	case *gnmi.TypedValue_BoolVal:
+		scalar = v.BoolVal
+	case *gnmi.TypedValue_BytesVal:
+		scalar = v.BytesVal
+	case *gnmi.TypedValue_IntVal:
+		scalar = v.IntVal
+	case *gnmi.TypedValue_UintVal:
+		scalar = v.UintVal
+	case *gnmi.TypedValue_FloatVal:
+		scalar = v.FloatVal
+	default:
+		// Decimal, leaf-list, Any and ASCII values are only sent as PROTO
+		return value, nil
+	}
+
+	data, err := json.Marshal(scalar)
+	if err != nil {
+		return nil, err
+	}
+	if encoding == gnmi.Encoding_JSON {
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: data}}, nil
+	}
+	return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: data}}, nil
+}
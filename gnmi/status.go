@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/internal/grpcerr"
+)
+
+// Error classes of failed subscriptions, check them with errors.Is
+var (
+	ErrAuth        = grpcerr.ErrAuth
+	ErrUnavailable = grpcerr.ErrUnavailable
+	ErrInvalid     = grpcerr.ErrInvalid
+)
+
+// Connection states reported for every device
+const (
+	StateConnecting = "connecting"
+	StateConnected  = "connected"
+	StateBackoff    = "backoff"
+	StateFailed     = "failed"
+	StateDone       = "done"
+	StateStopped    = "stopped"
+)
+
+// TargetState is the connection state of a device
+type TargetState struct {
+	Address    string    `json:"address"`
+	State      string    `json:"state"`
+	Since      time.Time `json:"since"`
+	Synced     bool      `json:"synced"`
+	Retries    int       `json:"retries"`
+	LastError  string    `json:"last_error,omitempty"`
+	ErrorClass string    `json:"error_class,omitempty"`
+}
+
+// Errors that will not go away by redialing
+func isPermanent(err error) bool {
+	return errors.Is(err, ErrAuth) || errors.Is(err, ErrInvalid)
+}
+
+// Name of the error class for the status endpoint
+func errorClass(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrAuth):
+		return "auth"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	case errors.Is(err, ErrInvalid):
+		return "invalid"
+	}
+	return "other"
+}
+
+// Exponential redial backoff with jitter
+type backoff struct {
+	base    time.Duration
+	max     time.Duration
+	attempt uint
+}
+
+// Delay before the next redial, a random delay between half and the full
+// backoff spreads the redials of devices failing at the same time
+func (b *backoff) next() time.Duration {
+	// Double the base for every attempt without shifting past the maximum
+	delay := b.base
+	for i := uint(0); i < b.attempt && delay < b.max; i++ {
+		if delay > b.max/2 {
+			delay = b.max
+		} else {
+			delay *= 2
+		}
+	}
+	if delay >= b.max {
+		delay = b.max
+	} else {
+		b.attempt++
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
+// Update the connection state of a device, the error is kept until the device connects
+func (c *CiscoTelemetryGNMI) setState(address string, state string, err error) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	if c.states == nil {
+		c.states = make(map[string]*TargetState)
+	}
+
+	current, ok := c.states[address]
+	if !ok {
+		current = &TargetState{Address: address}
+		c.states[address] = current
+	}
+	if current.State != state {
+		current.Since = time.Now()
+	}
+	current.State = state
+
+	switch {
+	case err != nil:
+		current.LastError, current.ErrorClass = err.Error(), errorClass(err)
+	case state == StateConnected:
+		current.LastError, current.ErrorClass, current.Retries = "", "", 0
+	}
+	if state == StateBackoff {
+		current.Retries++
+	}
+}
+
+// Whether a device reached the connected state since its last redial
+func (c *CiscoTelemetryGNMI) connected(address string) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	state, ok := c.states[address]
+	return ok && state.State == StateConnected
+}
+
+// Remove the state of a device that is gone, such as a closed dial-out connection
+func (c *CiscoTelemetryGNMI) deleteState(address string) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	delete(c.states, address)
+}
+
+// Status returns the connection state of every device
+func (c *CiscoTelemetryGNMI) Status() []TargetState {
+	c.stateMu.Lock()
+	states := make([]TargetState, 0, len(c.states))
+	for _, state := range c.states {
+		states = append(states, *state)
+	}
+	c.stateMu.Unlock()
+
+	for i := range states {
+		states[i].Synced = c.Synced(states[i].Address)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Address < states[j].Address })
+	return states
+}
+
+// Serve the device states as JSON on StatusAddress until ctx is done
+func (c *CiscoTelemetryGNMI) startStatus(ctx context.Context) error {
+	if len(c.StatusAddress) == 0 {
+		return nil
+	}
+
+	lis, err := net.Listen("tcp", c.StatusAddress)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", c.StatusAddress, err)
+	}
+	log.Printf("Serving connection status on http://%s/status", lis.Addr())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(c.Status()); err != nil {
+			log.Printf("failed to write status: %v", err)
+		}
+	})
+	server := &http.Server{Handler: mux}
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		if err := server.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Printf("Status endpoint failed: %v", err)
+		}
+	}()
+	go func() {
+		defer c.wg.Done()
+		<-ctx.Done()
+		server.Close()
+	}()
+	return nil
+}
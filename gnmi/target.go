@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Target overrides the collector settings for one device, settings left
+// empty are inherited from the collector
+type Target struct {
+	Address string
+
+	// Device credentials
+	Username string
+	Password string
+
+	// TLS settings
+	TLS *TLSSettings
+
+	// Target name, origin and path prefix of the requests
+	Target string
+	Origin string
+	Prefix string
+
+	// Additional metadata sent with every request, merged with the collector metadata
+	Metadata map[string]string
+
+	// Subscriptions replacing the collector subscriptions
+	Subscriptions []Subscription
+}
+
+// TLSSettings of a device connection
+type TLSSettings struct {
+	// Use plain text if false
+	Enable bool
+
+	// CA certificate file to verify the device, the system roots are used if empty
+	CA string
+
+	// Client certificate and key files, no client certificate is sent if empty
+	Cert string
+	Key  string
+
+	// Server name to verify, the host of the address if empty
+	ServerName string
+
+	// Skip verification of the device certificate
+	InsecureSkipVerify bool
+}
+
+// Resolve the devices in Addresses and Targets, settings left empty in a
+// target are taken from the collector
+func (c *CiscoTelemetryGNMI) targets() ([]*Target, error) {
+	targets := make([]*Target, 0, len(c.Addresses)+len(c.Targets))
+	for _, address := range c.Addresses {
+		targets = append(targets, c.resolveTarget(Target{Address: address}))
+	}
+	for _, target := range c.Targets {
+		targets = append(targets, c.resolveTarget(target))
+	}
+
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		if len(target.Address) == 0 {
+			return nil, fmt.Errorf("target address is empty")
+		} else if seen[target.Address] {
+			return nil, fmt.Errorf("duplicate target address %s", target.Address)
+		}
+		seen[target.Address] = true
+	}
+	return targets, nil
+}
+
+// Fill the empty settings of a target with the collector settings
+func (c *CiscoTelemetryGNMI) resolveTarget(target Target) *Target {
+	if len(target.Username) == 0 {
+		target.Username, target.Password = c.Username, c.Password
+	}
+	if target.TLS == nil {
+		target.TLS = c.TLS
+	}
+	if len(target.Target) == 0 {
+		target.Target = c.Target
+	}
+	if len(target.Origin) == 0 {
+		target.Origin = c.Origin
+	}
+	if len(target.Prefix) == 0 {
+		target.Prefix = c.Prefix
+	}
+	if target.Subscriptions == nil {
+		target.Subscriptions = c.Subscriptions
+	}
+
+	metadata := make(map[string]string, len(c.Metadata)+len(target.Metadata))
+	for key, val := range c.Metadata {
+		metadata[key] = val
+	}
+	for key, val := range target.Metadata {
+		metadata[key] = val
+	}
+	target.Metadata = metadata
+	return &target
+}
+
+// TLS settings for a device connection, nil for plain text
+func (t *Target) tlsConfig() (*tls.Config, error) {
+	// Devices without TLS settings keep the historic unverified TLS
+	if t.TLS == nil {
+		return &tls.Config{
+			InsecureSkipVerify: true,
+		}, nil
+	}
+	if !t.TLS.Enable {
+		return nil, nil
+	}
+
+	tlscfg := &tls.Config{
+		ServerName:         t.TLS.ServerName,
+		InsecureSkipVerify: t.TLS.InsecureSkipVerify,
+	}
+	if len(t.TLS.CA) > 0 {
+		pem, err := os.ReadFile(t.TLS.CA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		tlscfg.RootCAs = x509.NewCertPool()
+		if !tlscfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", t.TLS.CA)
+		}
+	}
+	if len(t.TLS.Cert) > 0 {
+		cert, err := tls.LoadX509KeyPair(t.TLS.Cert, t.TLS.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		tlscfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlscfg, nil
+}
+
+// Add the device credentials and metadata to the outgoing context
+func (t *Target) withCredentials(ctx context.Context) context.Context {
+	if len(t.Username) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "username", t.Username, "password", t.Password)
+	}
+	for key, val := range t.Metadata {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, val)
+	}
+	return ctx
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// Add the fields of a typed value named after name, nothing is added for an empty value
+func (c *CiscoTelemetryGNMI) addTypedValue(name string, val *gnmi.TypedValue, fields map[string]interface{}) {
+	switch v := val.GetValue().(type) {
+	case nil:
+	case *gnmi.TypedValue_LeaflistVal:
+		c.addLeafList(name, v.LeaflistVal, fields)
+	case *gnmi.TypedValue_AnyVal:
+		c.addAny(name, v.AnyVal, fields)
+	case *gnmi.TypedValue_JsonIetfVal:
+		addJSON(name, v.JsonIetfVal, fields)
+	case *gnmi.TypedValue_JsonVal:
+		addJSON(name, v.JsonVal, fields)
+	default:
+		value, err := scalarValue(val)
+		if err != nil {
+			log.Printf("failed to decode value of %s: %v", name, err)
+			return
+		}
+		fields[name] = value
+	}
+}
+
+// Decode a scalar typed value
+func scalarValue(val *gnmi.TypedValue) (interface{}, error) {
+	switch v := val.GetValue().(type) {
+	case *gnmi.TypedValue_AsciiVal:
+		return v.AsciiVal, nil
+	case *gnmi.TypedValue_BoolVal:
+		return v.BoolVal, nil
+	case *gnmi.TypedValue_BytesVal:
+		return v.BytesVal, nil
+	case *gnmi.TypedValue_DecimalVal:
+		return decimalValue(v.DecimalVal)
+	case *gnmi.TypedValue_FloatVal:
+		return v.FloatVal, nil
+	case *gnmi.TypedValue_IntVal:
+		return v.IntVal, nil
+	case *gnmi.TypedValue_StringVal:
+		return v.StringVal, nil
+	case *gnmi.TypedValue_UintVal:
+		return v.UintVal, nil
+	case *gnmi.TypedValue_ProtoBytes:
+		return v.ProtoBytes, nil
+	}
+	return nil, fmt.Errorf("unsupported scalar value %T", val.GetValue())
+}
+
+// Convert a Decimal64 to the nearest float, parsing the decimal representation avoids a second rounding
+func decimalValue(decimal *gnmi.Decimal64) (float64, error) {
+	digits := strconv.FormatInt(decimal.Digits, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	precision := int(decimal.Precision)
+	if precision > 0 {
+		if len(digits) <= precision {
+			digits = strings.Repeat("0", precision-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-precision] + "." + digits[len(digits)-precision:]
+	}
+	return strconv.ParseFloat(sign+digits, 64)
+}
+
+// Expand a leaf-list into indexed fields, or join it into a single field if a separator is set
+func (c *CiscoTelemetryGNMI) addLeafList(name string, leaflist *gnmi.ScalarArray, fields map[string]interface{}) {
+	if len(c.LeafListSeparator) == 0 {
+		for i, element := range leaflist.GetElement() {
+			c.addTypedValue(fmt.Sprintf("%s/%d", name, i), element, fields)
+		}
+		return
+	}
+
+	elements := make([]string, 0, len(leaflist.GetElement()))
+	for _, element := range leaflist.GetElement() {
+		value, err := scalarValue(element)
+		if err != nil {
+			log.Printf("failed to decode leaf-list element of %s: %v", name, err)
+			continue
+		}
+		elements = append(elements, fmt.Sprint(value))
+	}
+	fields[name] = strings.Join(elements, c.LeafListSeparator)
+}
+
+// Decode an Any value with the type registry and flatten its fields, the raw
+// bytes are kept if the type is unknown
+func (c *CiscoTelemetryGNMI) addAny(name string, value *any.Any, fields map[string]interface{}) {
+	marshaler := jsonpb.Marshaler{OrigName: true, AnyResolver: c.AnyResolver}
+	data, err := marshaler.MarshalToString(value)
+	if err != nil {
+		log.Printf("failed to decode Any value of %s with type %s: %v", name, value.GetTypeUrl(), err)
+		fields[name] = value.GetValue()
+		return
+	}
+
+	var decoded map[string]interface{}
+	if err = json.Unmarshal([]byte(data), &decoded); err != nil {
+		log.Printf("failed to parse Any value of %s: %v", name, err)
+		return
+	}
+	delete(decoded, "@type")
+	flattenJSON(name, decoded, fields)
+}
+
+// Parse a JSON value and flatten it into fields
+func addJSON(name string, data []byte, fields map[string]interface{}) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		log.Printf("failed to parse JSON value: %v", err)
+		return
+	}
+	flattenJSON(name, value, fields)
+}
+
+// Flatten a decoded JSON value into fields named after the JSON members
+func flattenJSON(name string, value interface{}, fields map[string]interface{}) {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		for key, member := range val {
+			// Strip the module prefix of JSON_IETF members
+			if i := strings.LastIndex(key, ":"); i >= 0 {
+				key = key[i+1:]
+			}
+			flattenJSON(name+"/"+strings.Replace(key, "-", "_", -1), member, fields)
+		}
+	case []interface{}:
+		for i, member := range val {
+			flattenJSON(fmt.Sprintf("%s/%d", name, i), member, fields)
+		}
+	case nil:
+	default:
+		fields[name] = val
+	}
+}
@@ -0,0 +1,32 @@
+/*
+Package grpcauth checks the username and password metadata sent by the
+telemetry clients with every gRPC call.
+*/
+
+package grpcauth
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Check the username and password metadata of an incoming call in constant
+// time, nothing is checked if username is empty. A mismatch returns a
+// codes.Unauthenticated status error.
+func Check(ctx context.Context, username, password string) error {
+	if len(username) == 0 {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	usernames, passwords := md.Get("username"), md.Get("password")
+	if len(usernames) != 1 || len(passwords) != 1 ||
+		subtle.ConstantTimeCompare([]byte(usernames[0]), []byte(username)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(passwords[0]), []byte(password)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid username or password")
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+/*
+Package grpcerr maps gRPC errors to the error classes shared by the
+telemetry clients.
+*/
+
+package grpcerr
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error classes, check them with errors.Is
+var (
+	ErrAuth        = errors.New("authentication failed")
+	ErrUnavailable = errors.New("device unavailable")
+	ErrInvalid     = errors.New("invalid request")
+)
+
+// Classify wraps err in the error class of its status code, errors without
+// a class are returned unchanged
+func Classify(err error) error {
+	switch Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return fmt.Errorf("%w: %v", ErrAuth, err)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	case codes.InvalidArgument, codes.NotFound, codes.Unimplemented:
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return err
+}
+
+// Code returns the status code of the first error in the chain of err that
+// has a gRPC status, such as errors returned by gRPC or errors implementing
+// GRPCStatus. It is codes.Unknown if there is none.
+func Code(err error) codes.Code {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if _, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+			return status.Code(err)
+		}
+	}
+	return codes.Unknown
+}
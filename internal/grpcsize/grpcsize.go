@@ -0,0 +1,26 @@
+/*
+Package grpcsize sets the gRPC receive limit of the dial-out servers from the
+maximum size of a telemetry payload.
+*/
+
+package grpcsize
+
+import (
+	"math"
+
+	"google.golang.org/grpc"
+)
+
+// Overhead is the room left for the fields of the gRPC message wrapping a
+// payload, e.g. the request ID and error text of MdtDialoutArgs
+const Overhead = 1024
+
+// ServerOption limits the received messages to a payload of maxPayload bytes
+// with its wrapper, the exact payload limit is left to the decoder. Zero or
+// less lifts the 4 MiB default of gRPC.
+func ServerOption(maxPayload int) grpc.ServerOption {
+	if maxPayload <= 0 || maxPayload > math.MaxInt32-Overhead {
+		return grpc.MaxRecvMsgSize(math.MaxInt32)
+	}
+	return grpc.MaxRecvMsgSize(maxPayload + Overhead)
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Rejected is a raw payload that could not be decoded, with the reason
+type Rejected struct {
+	Time time.Time `json:"time"`
+
+	// Device and encoding path, empty if the message itself could not be decoded
+	Device string `json:"device,omitempty"`
+	Path   string `json:"path,omitempty"`
+
+	// Index of the rejected row in the message, -1 if the whole message was rejected
+	Row int `json:"row"`
+
+	Error string `json:"error"`
+
+	// Serialized telemetry message, or the serialized GPB-KV entry or compact GPB row
+	Data []byte `json:"data"`
+}
+
+// DeadLetter stores rejected payloads as JSON lines, the data is base64
+// encoded. It is safe for concurrent use.
+type DeadLetter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewDeadLetter creates a dead-letter output writing to w
+func NewDeadLetter(w io.Writer) *DeadLetter {
+	return &DeadLetter{encoder: json.NewEncoder(w)}
+}
+
+// CreateDeadLetter opens a dead-letter file, rejected payloads are appended if it exists
+func CreateDeadLetter(path string) (*DeadLetter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &DeadLetter{encoder: json.NewEncoder(file), closer: file}, nil
+}
+
+// Write a rejected payload, the time is set if empty
+func (d *DeadLetter) Write(rejected *Rejected) error {
+	if rejected.Time.IsZero() {
+		rejected.Time = time.Now()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.encoder.Encode(rejected)
+}
+
+// Close the file of a dead-letter output created with CreateDeadLetter
+func (d *DeadLetter) Close() error {
+	if d.closer == nil {
+		return nil
+	}
+	return d.closer.Close()
+}
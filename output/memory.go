@@ -0,0 +1,45 @@
+package output
+
+import "sync"
+
+// Memory keeps all metrics in memory, to check the collected measurements in tests
+type Memory struct {
+	mu      sync.Mutex
+	metrics []*Metric
+}
+
+// Write appends a metric
+func (m *Memory) Write(metric *Metric) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = append(m.metrics, metric)
+	return nil
+}
+
+// Flush does nothing, metrics are kept until Reset
+func (m *Memory) Flush() error {
+	return nil
+}
+
+// Metrics returns the metrics written so far in order
+func (m *Memory) Metrics() []*Metric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metrics := make([]*Metric, len(m.metrics))
+	copy(metrics, m.metrics)
+	return metrics
+}
+
+// Len returns the number of metrics written so far
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.metrics)
+}
+
+// Reset removes all metrics
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = nil
+}
@@ -0,0 +1,98 @@
+/*
+Package output defines the destinations for decoded telemetry measurements.
+*/
+
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metric is a decoded telemetry measurement
+type Metric struct {
+	Name      string
+	Tags      map[string]string
+	Fields    map[string]interface{}
+	Timestamp time.Time
+
+	// Delete marks the fields as removed from the series identified by
+	// Name and Tags, field values are nil for delete events
+	Delete bool
+}
+
+// Output receives decoded metrics, implementations must be safe for concurrent use
+type Output interface {
+	Write(metric *Metric) error
+	Flush() error
+}
+
+// Printer writes metrics in a human readable form
+type Printer struct {
+	mu     sync.Mutex
+	writer *bufio.Writer
+}
+
+// NewPrinter creates a printer writing to w
+func NewPrinter(w io.Writer) *Printer {
+	return &Printer{writer: bufio.NewWriter(w)}
+}
+
+// Write a metric, the output is flushed after every metric
+func (p *Printer) Write(metric *Metric) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if metric.Delete {
+		fmt.Fprintf(p.writer, "\n\n###### Delete from %v ######\n", metric.Tags["source"])
+	} else {
+		fmt.Fprintf(p.writer, "\n\n###### New message from %v ######\n", metric.Tags["source"])
+	}
+	fmt.Fprintf(p.writer, "Measurement: %v\n", metric.Name)
+	fmt.Fprintf(p.writer, "Timestamp: %v\n", metric.Timestamp)
+
+	fmt.Fprintf(p.writer, "\n\n**** TAGS *****\n")
+	for _, key := range sortedKeys(metric.Tags) {
+		fmt.Fprintf(p.writer, "%v: %v\n", key, metric.Tags[key])
+	}
+
+	if metric.Delete {
+		fmt.Fprintf(p.writer, "\n\n**** Deleted *****\n")
+		for key := range metric.Fields {
+			fmt.Fprintf(p.writer, "%v\n", key)
+		}
+		return p.writer.Flush()
+	}
+
+	fmt.Fprintf(p.writer, "\n\n**** Values *****\n")
+	fields := make(map[string]string, len(metric.Fields))
+	for key, val := range metric.Fields {
+		fields[key] = fmt.Sprint(val)
+	}
+	for _, key := range sortedKeys(fields) {
+		fmt.Fprintf(p.writer, "%v: %v\n", key, fields[key])
+	}
+
+	return p.writer.Flush()
+}
+
+// Flush buffered output
+func (p *Printer) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.writer.Flush()
+}
+
+// Sorted keys for a stable output
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,279 @@
+/*
+Replay a telemetry capture, decoded offline or sent to another collector
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	mdtdialout "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt_dialout"
+	gnmidialout "github.com/CiscoSE/grpc_collector/gnmi/gnmi_dialout"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/proto"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	file     = flag.String("file", "", "Capture file to replay")
+	target   = flag.String("target", "", "Collector to send the payloads to as host:port, decodes offline if empty")
+	speed    = flag.Float64("speed", 1, "Replay speed relative to the capture, 0 replays as fast as possible")
+	username = flag.String("username", "", "Username sent to a gNMI dial-out collector")
+	password = flag.String("password", "", "Password sent to a gNMI dial-out collector")
+
+	// Limits of the offline decoded messages, zero is unlimited
+	maxDepth       = flag.Int("max-depth", 32, "Maximum nesting of GPB-KV fields, deeper fields are dropped")
+	maxFields      = flag.Int("max-fields", 10000, "Maximum fields per measurement, further GPB-KV fields are dropped")
+	maxMessageSize = flag.Int("max-message-size", 4<<20, "Maximum size of a telemetry message in bytes, larger messages are rejected")
+)
+
+// Replayer decodes or sends the records of a capture
+type Replayer struct {
+	// Collector address, records are decoded offline if empty
+	Target string
+
+	// Credentials for gNMI dial-out collectors
+	Username string
+	Password string
+
+	// Replay speed relative to the capture, zero replays as fast as possible
+	Speed float64
+
+	// Destination of the offline decoded MDT measurements
+	Output output.Output
+
+	// Limits of the offline decoding, zero is unlimited, see mdt.Decoder
+	MaxDepth       int
+	MaxFields      int
+	MaxMessageSize int
+
+	// Accounting of the offline decoded MDT messages, created by Replay if nil
+	Counters *mdt.Counters
+
+	decoder *mdt.Decoder
+	conn    *grpc.ClientConn
+
+	// Streams per peer of the capture, every device gets its own stream
+	mdtStreams  map[string]mdtdialout.GRPCMdtDialout_MdtDialoutClient
+	gnmiStreams map[string]gnmidialout.GNMIDialOut_PublishClient
+	reqID       int64
+}
+
+// Replay all records of reader until the end of the capture or until ctx is done
+func (r *Replayer) Replay(ctx context.Context, reader *capture.Reader) error {
+	if r.Counters == nil {
+		r.Counters = &mdt.Counters{}
+	}
+	r.decoder = &mdt.Decoder{
+		Compact:        mdt.KnownCompact(),
+		Counters:       r.Counters,
+		MaxDepth:       r.MaxDepth,
+		MaxFields:      r.MaxFields,
+		MaxMessageSize: r.MaxMessageSize,
+	}
+	r.mdtStreams = make(map[string]mdtdialout.GRPCMdtDialout_MdtDialoutClient)
+	r.gnmiStreams = make(map[string]gnmidialout.GNMIDialOut_PublishClient)
+	if r.Output == nil {
+		r.Output = output.NewPrinter(os.Stdout)
+	}
+
+	if len(r.Target) > 0 {
+		conn, err := grpc.DialContext(ctx, r.Target, grpc.WithInsecure())
+		if err != nil {
+			return fmt.Errorf("failed to dial %s: %v", r.Target, err)
+		}
+		r.conn = conn
+		defer conn.Close()
+		defer r.closeStreams()
+	}
+
+	var first time.Time
+	start := time.Now()
+	for count := 0; ; count++ {
+		record, err := reader.Next()
+		if err == io.EOF {
+			log.Printf("Replayed %d records", count)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read record %d: %v", count+1, err)
+		}
+
+		// Keep the original spacing of the records, scaled by the speed
+		if count == 0 {
+			first = record.Time
+		}
+		if r.Speed > 0 {
+			due := start.Add(time.Duration(float64(record.Time.Sub(first)) / r.Speed))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Until(due)):
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err = r.replay(ctx, record); err != nil {
+			return fmt.Errorf("failed to replay record %d from %s: %v", count+1, record.Peer, err)
+		}
+	}
+}
+
+// Decode or send a single record, only failures to send are returned. Payloads
+// that fail to decode are logged and skipped like in the collectors.
+func (r *Replayer) replay(ctx context.Context, record *capture.Record) error {
+	switch record.Transport {
+	case capture.MdtDialout, capture.MdtDialIn:
+		if r.conn == nil {
+			r.decodeMDT(record)
+			return nil
+		}
+		return r.sendMDT(ctx, record)
+	case capture.GNMI, capture.GNMIDialOut:
+		response := &gnmi.SubscribeResponse{}
+		if err := proto.Unmarshal(record.Data, response); err != nil {
+			log.Printf("Skipping invalid subscribe response from %s at %v: %v", record.Peer, record.Time, err)
+			return nil
+		}
+		if r.conn == nil {
+			// Measurements depend on the gNMI collector configuration, see its replay command
+			fmt.Printf("\n###### gNMI response from %s at %v ######\n%s", record.Peer, record.Time, proto.MarshalTextString(response))
+			return nil
+		}
+		return r.sendGNMI(ctx, record.Peer, response)
+	}
+	log.Printf("Skipping record of unknown %v from %s", record.Transport, record.Peer)
+	return nil
+}
+
+// Decode an MDT payload like the dial-out collector and write the measurements,
+// rows that fail to decode are logged, counted and skipped
+func (r *Replayer) decodeMDT(record *capture.Record) {
+	metrics, err := r.decoder.Decode(record.Data)
+	if err != nil {
+		log.Printf("Record from %s at %v: %v", record.Peer, record.Time, err)
+	}
+	for _, metric := range metrics {
+		if err := r.Output.Write(metric); err != nil {
+			log.Printf("Failed to write measurement: %v", err)
+		}
+	}
+}
+
+// Send an MDT payload over gRPC dial-out on the stream of its peer
+func (r *Replayer) sendMDT(ctx context.Context, record *capture.Record) error {
+	stream, ok := r.mdtStreams[record.Peer]
+	if !ok {
+		var err error
+		if stream, err = mdtdialout.NewGRPCMdtDialoutClient(r.conn).MdtDialout(ctx); err != nil {
+			return err
+		}
+		r.mdtStreams[record.Peer] = stream
+	}
+	r.reqID++
+	return stream.Send(&mdtdialout.MdtDialoutArgs{ReqId: r.reqID, Data: record.Data})
+}
+
+// Publish a subscribe response over gNMI dial-out on the stream of its peer
+func (r *Replayer) sendGNMI(ctx context.Context, peer string, response *gnmi.SubscribeResponse) error {
+	stream, ok := r.gnmiStreams[peer]
+	if !ok {
+		if len(r.Username) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, "username", r.Username, "password", r.Password)
+		}
+		var err error
+		if stream, err = gnmidialout.NewGNMIDialOutClient(r.conn).Publish(ctx); err != nil {
+			return err
+		}
+		r.gnmiStreams[peer] = stream
+	}
+	return stream.Send(response)
+}
+
+// Close the streams and wait for the collector to end them, so no payload is lost
+func (r *Replayer) closeStreams() {
+	for peer, stream := range r.mdtStreams {
+		stream.CloseSend()
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err != io.EOF {
+					log.Printf("Dial-out stream for %s failed: %v", peer, err)
+				}
+				break
+			}
+		}
+	}
+	for peer, stream := range r.gnmiStreams {
+		stream.CloseSend()
+		for {
+			if _, err := stream.Recv(); err != nil {
+				if err != io.EOF {
+					log.Printf("gNMI dial-out stream for %s failed: %v", peer, err)
+				}
+				break
+			}
+		}
+	}
+}
+
+func main() {
+	flag.Parse()
+	if len(*file) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	reader, err := capture.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open capture: %v", err)
+	}
+	defer reader.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-c:
+			fmt.Printf("\nManually cancelled the replay\n")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	replayer := &Replayer{
+		Target:   *target,
+		Username: *username,
+		Password: *password,
+		Speed:    *speed,
+
+		MaxDepth:       *maxDepth,
+		MaxFields:      *maxFields,
+		MaxMessageSize: *maxMessageSize,
+	}
+	if err = replayer.Replay(ctx, reader); err != nil {
+		log.Printf("Replay failed: %v", err)
+	}
+	if err = replayer.Output.Flush(); err != nil {
+		log.Printf("Failed to flush output: %v", err)
+	}
+	if len(*target) == 0 {
+		fmt.Printf("\nDecoded telemetry:\n")
+		replayer.Counters.Print(os.Stdout)
+	}
+}
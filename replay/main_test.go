@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/CiscoSE/grpc_collector/capture"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/emulator"
+	"github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt"
+	mdtdialout "github.com/CiscoSE/grpc_collector/cisco_telemetry_mdt/mdt_dialout"
+	"github.com/CiscoSE/grpc_collector/output"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// Dial-out collector running in-process, payloads are decoded like in
+// DialOutServer.handleTelemetry: errors are counted and the stream goes on
+type collector struct {
+	decoder *mdt.Decoder
+	memory  *output.Memory
+}
+
+func (c *collector) MdtDialout(stream mdtdialout.GRPCMdtDialout_MdtDialoutServer) error {
+	for {
+		packet, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		metrics, _ := c.decoder.Decode(packet.Data)
+		for _, metric := range metrics {
+			c.memory.Write(metric)
+		}
+	}
+}
+
+// Start a collector on a free port and return its address
+func startCollector(t *testing.T) (*collector, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &collector{
+		decoder: &mdt.Decoder{Compact: mdt.KnownCompact(), Counters: &mdt.Counters{}},
+		memory:  &output.Memory{},
+	}
+	server := grpc.NewServer()
+	mdtdialout.RegisterGRPCMdtDialoutServer(server, c)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return c, lis.Addr().String()
+}
+
+// Serialized message of the emulator, with the content of the first row replaced by invalid if set
+func payload(t *testing.T, path string, encoding emulator.Encoding, invalid []byte) []byte {
+	t.Helper()
+	generator := &emulator.Generator{Node: "router", Subscription: "sub", Path: path, Encoding: encoding, Rows: 2}
+	message, err := generator.Next(time.Date(2020, 9, 14, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invalid != nil {
+		message.DataGpb.Row[0].Content = invalid
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Capture with one MDT dial-out record per payload
+func newCapture(t *testing.T, payloads ...[]byte) *capture.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := capture.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2020, 9, 14, 12, 0, 0, 0, time.UTC)
+	for i, data := range payloads {
+		record := &capture.Record{Time: start.Add(time.Duration(i) * time.Millisecond), Transport: capture.MdtDialout, Peer: "192.0.2.1:57500", Data: data}
+		if err := writer.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := capture.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+// Sum of the counts of all devices and paths
+func total(counters *mdt.Counters) mdt.Count {
+	var sum mdt.Count
+	for _, count := range counters.Counts() {
+		sum.Messages += count.Messages
+		sum.Rows += count.Rows
+		sum.Errors += count.Errors
+	}
+	return sum
+}
+
+// Records that fail to decode are counted and skipped, the following records
+// are still decoded offline and by a collector
+func TestReplay(t *testing.T) {
+	lldp := payload(t, mdt.PathLLDPSummary, emulator.GPB, nil)
+	uribKV := payload(t, mdt.PathURIB, emulator.GPBKV, nil)
+	badRow := payload(t, mdt.PathURIB, emulator.GPB, []byte{0x0a, 0x05, 0x01})
+	garbage := []byte{0x0a, 0x05, 0x01}
+
+	tests := []struct {
+		name     string
+		payloads [][]byte
+		metrics  int
+		count    mdt.Count
+	}{
+		{"valid", [][]byte{lldp, uribKV}, 4, mdt.Count{Messages: 2, Rows: 4}},
+		{"invalid row", [][]byte{badRow, lldp}, 3, mdt.Count{Messages: 2, Rows: 3, Errors: 1}},
+		{"invalid message", [][]byte{lldp, garbage, uribKV}, 4, mdt.Count{Messages: 3, Rows: 4, Errors: 1}},
+		{"only invalid", [][]byte{garbage, badRow}, 1, mdt.Count{Messages: 2, Rows: 1, Errors: 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name+"/offline", func(t *testing.T) {
+			memory := &output.Memory{}
+			replayer := &Replayer{Output: memory}
+			if err := replayer.Replay(context.Background(), newCapture(t, test.payloads...)); err != nil {
+				t.Fatal(err)
+			}
+			if memory.Len() != test.metrics {
+				t.Errorf("got %d metrics, want %d", memory.Len(), test.metrics)
+			}
+			if got := total(replayer.Counters); got != test.count {
+				t.Errorf("got count %+v, want %+v", got, test.count)
+			}
+		})
+
+		t.Run(test.name+"/dial-out", func(t *testing.T) {
+			collector, address := startCollector(t)
+			replayer := &Replayer{Target: address, Output: &output.Memory{}}
+			if err := replayer.Replay(context.Background(), newCapture(t, test.payloads...)); err != nil {
+				t.Fatal(err)
+			}
+			if collector.memory.Len() != test.metrics {
+				t.Errorf("got %d metrics, want %d", collector.memory.Len(), test.metrics)
+			}
+			if got := total(collector.decoder.Counters); got != test.count {
+				t.Errorf("got count %+v, want %+v", got, test.count)
+			}
+		})
+	}
+}